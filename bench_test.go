@@ -0,0 +1,194 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+)
+
+// syntheticOutput returns device output of given size ended by banner
+func syntheticOutput(size int) []byte {
+	output := &bytes.Buffer{}
+	for i := 0; output.Len() < size; i++ {
+		fmt.Fprintf(output,
+			"? (192.168.%d.%d) at 70:85:c2:6c:e8:a3 [ether]  on br0\r\n",
+			i/256%256, i%256)
+	}
+	output.WriteString("admin@RT-N14U:/tmp/home/root# ")
+
+	return output.Bytes()
+}
+
+func benchmarkReadUntilPrompt(b *testing.B, size int) {
+	data := syntheticOutput(size)
+	reader := bytes.NewReader(data)
+	tc := &TelnetClient{
+		Delimiter: defaultDelimiter,
+		BannerRe:  defaultBannerRe,
+		reader:    bufio.NewReader(reader),
+	}
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		reader.Reset(data)
+		tc.reader.Reset(reader)
+
+		_, err := tc.ReadUntilPrompt(func(chunk []byte) bool {
+			return tc.BannerRe.Match(chunk)
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadUntilPrompt_4KB(b *testing.B) { benchmarkReadUntilPrompt(b, 4*1024) }
+func BenchmarkReadUntilPrompt_1MB(b *testing.B) { benchmarkReadUntilPrompt(b, 1024*1024) }
+
+func benchmarkReadUntilBanner(b *testing.B, size int) {
+	data := syntheticOutput(size)
+	reader := bytes.NewReader(data)
+	tc := &TelnetClient{
+		Delimiter: defaultDelimiter,
+		BannerRe:  defaultBannerRe,
+		reader:    bufio.NewReader(reader),
+	}
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		reader.Reset(data)
+		tc.reader.Reset(reader)
+
+		_, err := tc.ReadUntilBanner()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadUntilBanner_4KB(b *testing.B) { benchmarkReadUntilBanner(b, 4*1024) }
+func BenchmarkReadUntilBanner_1MB(b *testing.B) { benchmarkReadUntilBanner(b, 1024*1024) }
+
+func BenchmarkExecute(b *testing.B) {
+	data := syntheticOutput(16 * 1024)
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout: defaultReadTimeout,
+		Delimiter:   defaultDelimiter,
+		BannerRe:    defaultBannerRe,
+	}
+	tc.setConn(client)
+
+	// server
+	go func() {
+		reader := bufio.NewReader(server)
+		for {
+			if _, err := reader.ReadBytes('\n'); err != nil {
+				return
+			}
+			server.Write(data)
+		}
+	}()
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := tc.Execute("arp", "-a")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Test_allocationBudget keeps allocations per read bounded: they must
+// not grow with output size, as matching on growing buffer did
+func Test_allocationBudget(t *testing.T) {
+	if raceEnabled {
+		t.Skip("race detector changes allocations")
+	}
+
+	tests := []struct {
+		name   string
+		size   int
+		budget float64
+	}{
+		{"ReadUntilBanner 4KB", 4 * 1024, 8},
+		{"ReadUntilBanner 1MB", 1024 * 1024, 40},
+	}
+
+	for _, tt := range tests {
+		data := syntheticOutput(tt.size)
+		reader := bytes.NewReader(data)
+		tc := &TelnetClient{
+			Delimiter: defaultDelimiter,
+			BannerRe:  defaultBannerRe,
+			reader:    bufio.NewReader(reader),
+		}
+
+		allocs := testing.AllocsPerRun(3, func() {
+			reader.Reset(data)
+			tc.reader.Reset(reader)
+
+			if _, err := tc.ReadUntilBanner(); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if allocs > tt.budget {
+			t.Errorf("[%s] allocations per run: fact = %v, budget = %v", tt.name, allocs, tt.budget)
+		}
+	}
+}
+
+func Test_allocationBudgetExecute(t *testing.T) {
+	const budget = 40
+
+	if raceEnabled {
+		t.Skip("race detector changes allocations")
+	}
+
+	data := syntheticOutput(16 * 1024)
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout: defaultReadTimeout,
+		Delimiter:   defaultDelimiter,
+		BannerRe:    defaultBannerRe,
+	}
+	tc.setConn(client)
+
+	// server
+	go func() {
+		reader := bufio.NewReader(server)
+		for {
+			if _, err := reader.ReadBytes('\n'); err != nil {
+				return
+			}
+			server.Write(data)
+		}
+	}()
+
+	// Allocations of server are counted too
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, err := tc.Execute("arp", "-a"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > budget {
+		t.Errorf("Execute: allocations per run: fact = %v, budget = %v", allocs, budget)
+	}
+}
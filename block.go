@@ -0,0 +1,95 @@
+package telnet
+
+import (
+	"strings"
+	"time"
+)
+
+const defaultBlockSettle = 200 * time.Millisecond
+
+// ExecuteBlock sends several lines at once (e.g. "banner motd" text or
+// route-policy body) and returns their whole output. Intermediate prompts
+// printed after each line are kept in output, questions of AutoConfirm
+// are answered. Reading ends at the prompt, which isn't followed by more
+// output during BlockSettle
+func (tc *TelnetClient) ExecuteBlock(lines ...string) (output []byte, err error) {
+	tc.execMu.Lock()
+	defer tc.execMu.Unlock()
+	defer tc.markActive()
+
+	for _, line := range lines {
+		err = tc.checkPolicy(line)
+		if err != nil {
+			return
+		}
+	}
+	command := strings.Join(lines, "\n")
+	if tc.DryRun {
+		return tc.recordDryRun(command), nil
+	}
+
+	err = tc.discardBuffered()
+	if err != nil {
+		return
+	}
+	tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+
+	tc.limitCommand()
+	tc.log("Send block of %d lines", len(lines))
+	_, err = tc.write(tc.outgoingCR([]byte(strings.Join(lines, "\r\n") + "\r\n")))
+	if err != nil {
+		return
+	}
+
+	var data, matched []byte
+	for {
+		data, matched, err = tc.readUntilBannerConfirming()
+		output = append(output, data...)
+		if err != nil || !tc.moreOutput() {
+			break
+		}
+	}
+
+	output = tc.trimBanner(output, matched)
+	if err != nil {
+		err = &ExecuteError{
+			Command: command,
+			Output:  output,
+			Err:     err,
+			Tags:    tc.Tags,
+		}
+		return
+	}
+	if len(lines) > 0 {
+		output = tc.stripEcho(output, lines[0])
+	}
+	tc.log("Received data with size = %d", len(output))
+
+	return
+}
+
+// moreOutput reports whether server sends something during BlockSettle.
+// Received byte is returned to the stream
+func (tc *TelnetClient) moreOutput() bool {
+	if tc.buffered() > 0 {
+		return true
+	}
+
+	settle := tc.BlockSettle
+	if settle == 0 {
+		settle = defaultBlockSettle
+	}
+	deadline := tc.deadline
+	defer tc.setReadDeadline(deadline)
+	if until := time.Now().Add(settle); deadline.IsZero() || until.Before(deadline) {
+		tc.setReadDeadline(until)
+	}
+
+	b, err := tc.readByte()
+	if err != nil {
+		return false
+	}
+	tc.unread([]byte{b})
+
+	return true
+}
@@ -0,0 +1,57 @@
+package telnet
+
+import (
+	"bytes"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_ExecuteBlock(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout: time.Second,
+		Delimiter:   defaultDelimiter,
+		BannerRe:    regexp.MustCompile(`R1\(config[^)]*\)#`),
+		EchoMode:    EchoAuto,
+		BlockSettle: 100 * time.Millisecond,
+	}
+	tc.setConn(client)
+
+	// server
+	received := make(chan string, 1)
+	go func() {
+		buf := make([]byte, 128)
+		n, _ := server.Read(buf)
+		received <- string(buf[:n])
+
+		for _, chunk := range []string{
+			"route-policy DENY\r\nR1(config-rpl)#",
+			"drop\r\nR1(config-rpl)#",
+			"end-policy\r\nR1(config)#",
+		} {
+			server.Write([]byte(chunk))
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+
+	output, err := tc.ExecuteBlock("route-policy DENY", "drop", "end-policy")
+	if err != nil {
+		t.Fatalf("ExecuteBlock: unexpected error: %v", err)
+	}
+
+	if block := <-received; block != "route-policy DENY\r\ndrop\r\nend-policy\r\n" {
+		t.Errorf("ExecuteBlock: wrong block sent: %q", block)
+	}
+
+	want := []byte("R1(config-rpl)#drop\r\nR1(config-rpl)#end-policy\r\n")
+	if !bytes.Equal(output, want) {
+		t.Errorf(
+			"ExecuteBlock: wrong output:\n\t\tfact = %q\n\t\twant = %q",
+			output, want)
+	}
+}
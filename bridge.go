@@ -0,0 +1,136 @@
+package telnet
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// bridgeBufferSize is a maximum size of data passed in one write
+const bridgeBufferSize = 4 * 1024
+
+// bridgePollInterval is a period of checking, whether bridge is stopped,
+// while session output is awaited
+const bridgePollInterval = 100 * time.Millisecond
+
+// bridgeState stops both directions of Bridge
+type bridgeState struct {
+	mu      sync.Mutex
+	stopped bool
+	stop    chan struct{}
+}
+
+// close stops both directions, it returns after write
+// of stream data to the session in progress is done
+func (b *bridgeState) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.stopped {
+		b.stopped = true
+		close(b.stop)
+	}
+}
+
+// Bridge pipes stream (SSH channel, websocket, PTY) to the session
+// both ways. Telnet commands are removed from session output,
+// IAC bytes of stream data are escaped. Reading isn't limited by timeouts.
+// Bridge returns, when one of directions ends, the other one is stopped
+// and the session isn't used after return. Read of stream can't be
+// interrupted, it is left pending and its data is dropped
+func (tc *TelnetClient) Bridge(stream io.ReadWriter) (err error) {
+	defer tc.use()()
+
+	state := &bridgeState{stop: make(chan struct{})}
+	outputCh := make(chan error, 1)
+	inputCh := make(chan error, 1)
+	go func() {
+		outputCh <- tc.bridgeOutput(stream, state)
+	}()
+	go func() {
+		inputCh <- tc.bridgeInput(stream, state)
+	}()
+
+	select {
+	case err = <-outputCh:
+		state.close()
+	case err = <-inputCh:
+		state.close()
+		<-outputCh
+	}
+	if err == io.EOF {
+		err = nil
+	}
+
+	return
+}
+
+// bridgeOutput copies session output to stream until bridge is stopped.
+// Output is read with short deadlines, so stop is noticed without data
+func (tc *TelnetClient) bridgeOutput(stream io.Writer, state *bridgeState) (err error) {
+	var b byte
+	buf := make([]byte, 0, bridgeBufferSize)
+
+	for {
+		select {
+		case <-state.stop:
+			return nil
+		default:
+		}
+
+		if tc.buffered() == 0 {
+			tc.setReadDeadline(time.Now().Add(bridgePollInterval))
+		}
+		b, err = tc.nextByte()
+		if isTimeout(err) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		buf = append(buf[:0], b)
+
+		for tc.buffered() > 0 && len(buf) < bridgeBufferSize {
+			b, err = tc.nextByte()
+			if err != nil {
+				break
+			}
+			buf = append(buf, b)
+		}
+
+		if _, err = stream.Write(buf); err != nil {
+			return
+		}
+	}
+}
+
+// bridgeInput sends stream data to the session until bridge is stopped
+func (tc *TelnetClient) bridgeInput(stream io.Reader, state *bridgeState) (err error) {
+	var n int
+	buf := make([]byte, bridgeBufferSize)
+
+	for {
+		n, err = stream.Read(buf)
+		if n > 0 {
+			if werr := tc.bridgeWrite(buf[:n], state); werr != nil {
+				return werr
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// bridgeWrite sends stream data to the session, if bridge isn't stopped
+func (tc *TelnetClient) bridgeWrite(data []byte, state *bridgeState) (err error) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.stopped {
+		return io.EOF
+	}
+	_, err = tc.write(tc.outgoingCR(escapeIAC(data)))
+
+	return
+}
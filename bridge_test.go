@@ -0,0 +1,141 @@
+package telnet
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_Bridge(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	local, remote := net.Pipe()
+
+	tc := &TelnetClient{ReadTimeout: time.Second}
+	tc.setConn(client)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tc.Bridge(local)
+	}()
+
+	// session output without telnet commands goes to stream
+	go server.Write([]byte("R1>\xff\xf1 show\r\n"))
+
+	want := []byte("R1> show\r\n")
+	got := make([]byte, 0, len(want))
+	buf := make([]byte, 16)
+	for len(got) < len(want) {
+		n, err := remote.Read(buf)
+		if err != nil {
+			t.Fatalf("Bridge: unexpected error of stream: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if bytes.Compare(got, want) != 0 {
+		t.Errorf("Bridge: wrong output:\n\t\tfact = %q\n\t\twant = %q", got, want)
+	}
+
+	// stream data goes to session with IAC escaped
+	go remote.Write([]byte("ver\xff\r\n"))
+
+	want = []byte("ver\xff\xff\r\n")
+	got = got[:0]
+	for len(got) < len(want) {
+		n, err := server.Read(buf)
+		if err != nil {
+			t.Fatalf("Bridge: unexpected error of session: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if bytes.Compare(got, want) != 0 {
+		t.Errorf("Bridge: wrong input:\n\t\tfact = %q\n\t\twant = %q", got, want)
+	}
+
+	remote.Close()
+	if err := <-done; err != nil && err != io.ErrClosedPipe {
+		t.Errorf("Bridge: unexpected error: %v", err)
+	}
+	tc.Close()
+}
+
+// closedStream ends at once and records written data
+type closedStream struct {
+	mu      sync.Mutex
+	written []byte
+}
+
+func (s *closedStream) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (s *closedStream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.written = append(s.written, p...)
+
+	return len(p), nil
+}
+
+func Test_TelnetClient_BridgeStreamEnd(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	tc := &TelnetClient{ReadTimeout: time.Second}
+	tc.setConn(client)
+	defer tc.Close()
+
+	stream := &closedStream{}
+	if err := tc.Bridge(stream); err != nil {
+		t.Fatalf("Bridge stream end: unexpected error: %v", err)
+	}
+
+	// session output isn't read by bridge after return
+	time.Sleep(10 * time.Millisecond)
+	go server.Write([]byte("R1> "))
+
+	tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+	output := []byte{}
+	if _, err := tc.ReadUntil(&output, ' '); err != nil || string(output) != "R1> " {
+		t.Errorf("Bridge stream end: wrong output: %q, %v", output, err)
+	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	if len(stream.written) != 0 {
+		t.Errorf("Bridge stream end: session is read after return: %q", stream.written)
+	}
+}
+
+func Test_TelnetClient_BridgeSessionEnd(t *testing.T) {
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+
+	// reading of dry run session ends at once
+	tc := &TelnetClient{ReadTimeout: time.Second, DryRun: true}
+	tc.setConn(dryRunTransport{})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tc.Bridge(local)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Bridge session end: unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Bridge session end: bridge isn't stopped")
+	}
+
+	// stream data isn't sent to session after return
+	remote.Write([]byte("show\r\n"))
+	time.Sleep(10 * time.Millisecond)
+	if data := tc.DryRunData(); len(data) != 0 {
+		t.Errorf("Bridge session end: stream data is sent after return: %q", data)
+	}
+}
@@ -0,0 +1,117 @@
+package telnet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownChannel is returned when channel is not registered
+var ErrUnknownChannel = errors.New("telnet: unknown channel")
+
+// Channel is a virtual port of terminal server, which multiplexes
+// several ports over a single connection. Active port is switched
+// by the escape sequence sent by client
+type Channel struct {
+	Name   string
+	Escape []byte
+
+	tc      *TelnetClient
+	pending bytes.Buffer
+}
+
+// RegisterChannel registers virtual port, which becomes active
+// after escape sequence is sent
+func (tc *TelnetClient) RegisterChannel(name string, escape []byte) *Channel {
+	if tc.channels == nil {
+		tc.channels = make(map[string]*Channel)
+	}
+
+	ch := &Channel{Name: name, Escape: escape, tc: tc}
+	tc.channels[name] = ch
+
+	return ch
+}
+
+// Channel returns registered channel by name or nil
+func (tc *TelnetClient) Channel(name string) *Channel {
+	return tc.channels[name]
+}
+
+// ActiveChannel returns channel, which output is read now, or nil
+func (tc *TelnetClient) ActiveChannel() *Channel {
+	return tc.activeChannel
+}
+
+// SwitchChannel sends escape sequence of channel and makes it active.
+// Output of previous channel received but not read yet is kept
+// in its pending buffer
+func (tc *TelnetClient) SwitchChannel(name string) (err error) {
+	defer tc.use()()
+
+	ch, ok := tc.channels[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownChannel, name)
+	}
+	if ch == tc.activeChannel {
+		return
+	}
+
+	err = tc.stashBuffered()
+	if err != nil {
+		return
+	}
+
+	tc.log("Switch to channel %s", name)
+	_, err = tc.write(ch.Escape)
+	if err != nil {
+		return
+	}
+	tc.activeChannel = ch
+
+	return
+}
+
+// stashBuffered moves received but not read data to pending buffer
+// of active channel
+func (tc *TelnetClient) stashBuffered() (err error) {
+	var b byte
+
+	if tc.activeChannel == nil {
+		return
+	}
+
+	for tc.buffered() > 0 {
+		b, err = tc.nextByte()
+		if err != nil {
+			return
+		}
+		tc.activeChannel.pending.WriteByte(b)
+	}
+
+	return
+}
+
+// Pending returns and clears output of channel, which was received
+// before switching to another one
+func (ch *Channel) Pending() []byte {
+	if ch == ch.tc.activeChannel {
+		ch.tc.stashBuffered()
+	}
+
+	data := make([]byte, ch.pending.Len())
+	copy(data, ch.pending.Bytes())
+	ch.pending.Reset()
+
+	return data
+}
+
+// Execute switches to channel and executes command on it
+func (ch *Channel) Execute(name string, args ...string) (stdout []byte, err error) {
+	err = ch.tc.SwitchChannel(ch.Name)
+	if err != nil {
+		return
+	}
+
+	return ch.tc.Execute(name, args...)
+}
@@ -0,0 +1,53 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_TelnetClient_SwitchChannel(t *testing.T) {
+	sent := &bytes.Buffer{}
+	tc := &TelnetClient{
+		reader: bufio.NewReader(strings.NewReader(
+			"%LINK-3-UPDOWN: Interface Gi0/1, changed state to up\r\n")),
+		writer: bufio.NewWriter(sent),
+	}
+
+	port1 := tc.RegisterChannel("port1", []byte{0x1e, '1'})
+	port2 := tc.RegisterChannel("port2", []byte{0x1e, '2'})
+
+	if err := tc.SwitchChannel("port1"); err != nil {
+		t.Fatalf("SwitchChannel: unexpected error: %v", err)
+	}
+
+	// port1 output arrives
+	tc.reader.Peek(1)
+
+	if err := tc.SwitchChannel("port2"); err != nil {
+		t.Fatalf("SwitchChannel: unexpected error: %v", err)
+	}
+	if tc.ActiveChannel() != port2 {
+		t.Errorf("SwitchChannel: port2 is not active")
+	}
+	if bytes.Compare(sent.Bytes(), []byte{0x1e, '1', 0x1e, '2'}) != 0 {
+		t.Errorf("SwitchChannel: wrong escape sequences: %v", sent.Bytes())
+	}
+
+	want := []byte("%LINK-3-UPDOWN: Interface Gi0/1, changed state to up\r\n")
+	if fact := port1.Pending(); bytes.Compare(fact, want) != 0 {
+		t.Errorf(
+			"SwitchChannel: wrong pending data:\n\t\tfact = %q\n\t\twant = %q",
+			fact, want)
+	}
+	if fact := port2.Pending(); len(fact) != 0 {
+		t.Errorf("SwitchChannel: unexpected pending data of port2: %q", fact)
+	}
+
+	err := tc.SwitchChannel("port3")
+	if !errors.Is(err, ErrUnknownChannel) {
+		t.Errorf("SwitchChannel: unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package telnet
+
+// Command is a telnet command code, which follows IAC
+type Command byte
+
+//go:generate go run gen_names.go
+
+// Telnet commands (RFC 854, RFC 885, RFC 1184). Name of command
+// is the name of constant without Cmd prefix
+const (
+	// CmdEOF is end of file
+	CmdEOF Command = 236
+	// CmdSUSP suspends the current process
+	CmdSUSP Command = 237
+	// CmdABORT aborts the process
+	CmdABORT Command = 238
+	// CmdEOR marks end of record
+	CmdEOR Command = 239
+	// CmdSE is end of sub negotiation parameters
+	CmdSE Command = 240
+	// CmdNOP is no operation
+	CmdNOP Command = 241
+	// CmdDM is data mark, the data stream portion of a Synch
+	CmdDM Command = 242
+	// CmdBRK is NVT character BRK
+	CmdBRK Command = 243
+	// CmdIP interrupts process
+	CmdIP Command = 244
+	// CmdAO aborts output
+	CmdAO Command = 245
+	// CmdAYT is "are you there"
+	CmdAYT Command = 246
+	// CmdEC erases character
+	CmdEC Command = 247
+	// CmdEL erases line
+	CmdEL Command = 248
+	// CmdGA is go ahead signal
+	CmdGA Command = 249
+	// CmdSB is sub negotiation of the indicated option follows
+	CmdSB Command = 250
+	// CmdWILL indicate the desire to begin
+	CmdWILL Command = 251
+	// CmdWONT indicate the refusal to perform,
+	// continue performing, the indicated option
+	CmdWONT Command = 252
+	// CmdDO indicate the request that the other
+	// party perform, or confirmation that you are
+	// expecting the other party to perform, the indicated option
+	CmdDO Command = 253
+	// CmdDONT indicate the demand that the other
+	// party stop performing, or confirmation that you
+	// are no longer expecting the other party to
+	// perform, the indicated option
+	CmdDONT Command = 254
+	// CmdIAC interpret as command
+	CmdIAC Command = 255
+)
+
+// Untyped command codes, so they can be used in byte slices
+// and compared with bytes, e.g. []byte{IAC, DO, 1}
+const (
+	// IAC interpret as command
+	IAC = 255
+	// SB is sub negotiation of the indicated option follows
+	SB = 250
+	// SE is end of sub negotiation parameters
+	SE = 240
+	// WILL indicate the desire to begin
+	WILL = 251
+	// WONT indicate the refusal to perform,
+	// continue performing, the indicated option
+	WONT = 252
+	// DO indicate the request that the other
+	// party perform, or confirmation that you are
+	// expecting the other party to perform, the indicated option
+	DO = 253
+	// DONT indicate the demand that the other
+	// party stop performing, or confirmation that you
+	// are no longer expecting the other party to
+	// perform, the indicated option
+	DONT = 254
+)
+
+// Option is a telnet option code, which follows WILL, WONT, DO, DONT and SB
+type Option byte
+
+// Telnet options. Line comment starts with name of option
+const (
+	OptBinary             Option = 0   // BINARY, RFC 856
+	OptEcho               Option = 1   // ECHO, RFC 857
+	OptReconnection       Option = 2   // RCP, NIC 15391
+	OptSuppressGoAhead    Option = 3   // SGA, RFC 858
+	OptMessageSize        Option = 4   // NAMS, NIC 15393
+	OptStatus             Option = 5   // STATUS, RFC 859
+	OptTimingMark         Option = 6   // TIMING-MARK, RFC 860
+	OptRCTE               Option = 7   // RCTE, RFC 726
+	OptLineWidth          Option = 8   // NAOL, NIC 20196
+	OptPageSize           Option = 9   // NAOP, NIC 20197
+	OptNAOCRD             Option = 10  // NAOCRD, RFC 652
+	OptNAOHTS             Option = 11  // NAOHTS, RFC 653
+	OptNAOHTD             Option = 12  // NAOHTD, RFC 654
+	OptNAOFFD             Option = 13  // NAOFFD, RFC 655
+	OptNAOVTS             Option = 14  // NAOVTS, RFC 656
+	OptNAOVTD             Option = 15  // NAOVTD, RFC 657
+	OptNAOLFD             Option = 16  // NAOLFD, RFC 658
+	OptExtendedASCII      Option = 17  // EXTEND-ASCII, RFC 698
+	OptLogout             Option = 18  // LOGOUT, RFC 727
+	OptByteMacro          Option = 19  // BM, RFC 735
+	OptDataEntryTerminal  Option = 20  // DET, RFC 1043
+	OptSUPDUP             Option = 21  // SUPDUP, RFC 736
+	OptSUPDUPOutput       Option = 22  // SUPDUP-OUTPUT, RFC 749
+	OptSendLocation       Option = 23  // SEND-LOCATION, RFC 779
+	OptTerminalType       Option = 24  // TTYPE, RFC 1091
+	OptEndOfRecord        Option = 25  // EOR, RFC 885
+	OptTACACSUserID       Option = 26  // TUID, RFC 927
+	OptOutputMarking      Option = 27  // OUTMRK, RFC 933
+	OptTerminalLocation   Option = 28  // TTYLOC, RFC 946
+	Opt3270Regime         Option = 29  // 3270-REGIME, RFC 1041
+	OptX3PAD              Option = 30  // X.3-PAD, RFC 1053
+	OptNAWS               Option = 31  // NAWS, RFC 1073
+	OptTerminalSpeed      Option = 32  // TSPEED, RFC 1079
+	OptToggleFlowControl  Option = 33  // LFLOW, RFC 1372
+	OptLinemode           Option = 34  // LINEMODE, RFC 1184
+	OptXDisplayLocation   Option = 35  // XDISPLOC, RFC 1096
+	OptOldEnviron         Option = 36  // OLD-ENVIRON, RFC 1408
+	OptAuthentication     Option = 37  // AUTHENTICATION, RFC 2941
+	OptEncrypt            Option = 38  // ENCRYPT, RFC 2946
+	OptNewEnviron         Option = 39  // NEW-ENVIRON, RFC 1572
+	OptCharset            Option = 42  // CHARSET, RFC 2066
+	OptComPortControl     Option = 44  // COM-PORT-OPTION, RFC 2217
+	OptStartTLS           Option = 46  // START-TLS, draft-altman-telnet-starttls
+	OptMSDP               Option = 69  // MSDP, Mud Server Data Protocol
+	OptMSSP               Option = 70  // MSSP, Mud Server Status Protocol
+	OptCompress           Option = 85  // COMPRESS, MCCP v1
+	OptCompress2          Option = 86  // COMPRESS2, MCCP v2
+	OptMSP                Option = 90  // MSP, Mud Sound Protocol
+	OptMXP                Option = 91  // MXP, Mud eXtension Protocol
+	OptZMP                Option = 93  // ZMP, Zenith Mud Protocol
+	OptATCP               Option = 200 // ATCP, Achaea Telnet Client Protocol
+	OptGMCP               Option = 201 // GMCP, Generic Mud Communication Protocol
+	OptExtendedOptionList Option = 255 // EXOPL, RFC 861
+)
+
+// negotiation returns IAC sequence of command with option
+func negotiation(cmd Command, opt Option) []byte {
+	return []byte{IAC, byte(cmd), byte(opt)}
+}
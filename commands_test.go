@@ -0,0 +1,38 @@
+package telnet
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_Command_String(t *testing.T) {
+	tests := []struct {
+		value fmt.Stringer
+		want  string
+	}{
+		{Command(IAC), "IAC"},
+		{CmdEOF, "EOF"},
+		{CmdIAC, "IAC"},
+		{Command(100), "Command(100)"},
+		{OptNAWS, "NAWS"},
+		{OptCompress2, "COMPRESS2"},
+		{Option(120), "Option(120)"},
+	}
+
+	for _, tt := range tests {
+		if fact := tt.value.String(); fact != tt.want {
+			t.Errorf("String: fact = %q, want = %q", fact, tt.want)
+		}
+	}
+}
+
+func Test_untypedCommands(t *testing.T) {
+	// Untyped codes are usable with bytes and typed commands
+	sequence := []byte{IAC, DO, byte(OptEcho)}
+	if b := sequence[0]; b != IAC || Command(sequence[1]) != CmdDO {
+		t.Errorf("untyped commands: wrong sequence %v", sequence)
+	}
+	if string(negotiation(DO, OptEcho)) != string(sequence) {
+		t.Errorf("untyped commands: wrong negotiation %v", negotiation(DO, OptEcho))
+	}
+}
@@ -0,0 +1,60 @@
+package telnet
+
+import (
+	"regexp"
+	"sort"
+)
+
+// readUntilBannerConfirming reads command output until banner
+// like ReadUntilBanner, but answers questions of AutoConfirm on the way.
+// Questions and answers echoed by server are kept in output.
+// Prompts under the current one of prompt stack are matched too,
+// the stack is popped to the matched one (e.g. after "exit").
+// Output isn't trimmed, matched banner is returned separately
+func (tc *TelnetClient) readUntilBannerConfirming() (output []byte, matched []byte, err error) {
+	if len(tc.AutoConfirm) == 0 && len(tc.promptStack) == 0 {
+		return tc.readUntilRegexp(tc.BannerRe)
+	}
+
+	// Prompts go first, questions are sorted, so matching order
+	// doesn't depend on map iteration
+	levels := tc.promptLevels()
+	questions := make([]*regexp.Regexp, 0, len(tc.AutoConfirm))
+	for re := range tc.AutoConfirm {
+		questions = append(questions, re)
+	}
+	sort.Slice(questions, func(i, j int) bool {
+		return questions[i].String() < questions[j].String()
+	})
+	res := append(levels, questions...)
+
+	for {
+		var data []byte
+		var loc []int
+		var found int
+
+		data, loc, err = tc.readUntilMatch(func(window []byte) (loc []int) {
+			found, loc = findFirst(window, res...)
+			return
+		})
+		output = append(output, data...)
+		if err != nil {
+			return
+		}
+
+		// Unmatched prompt marked by EOR is considered as banner
+		if found < len(levels) {
+			for ; found > 0; found-- {
+				tc.PopPrompt()
+			}
+			return output, data[loc[0]:loc[1]], nil
+		}
+
+		answer := tc.AutoConfirm[res[found]]
+		tc.log("Found confirmation %q, answer %q", data[loc[0]:loc[1]], answer)
+		_, err = tc.write([]byte(answer + "\r\n"))
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package telnet
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_AutoConfirm(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout: time.Second,
+		Delimiter:   defaultDelimiter,
+		BannerRe:    regexp.MustCompile("R1#"),
+		AutoConfirm: map[*regexp.Regexp]string{
+			regexp.MustCompile("\\[confirm\\]"): "",
+			regexp.MustCompile("\\(y/n\\)"):     "y",
+		},
+	}
+	tc.setConn(client)
+
+	// server
+	answers := make(chan []string, 1)
+	go func() {
+		var received []string
+		buf := make([]byte, 64)
+
+		n, _ := server.Read(buf)
+		received = append(received, string(buf[:n]))
+		server.Write([]byte("Delete flash:old.bin? (y/n) "))
+		n, _ = server.Read(buf)
+		received = append(received, string(buf[:n]))
+		server.Write([]byte("y\r\nProceed with reload? [confirm]"))
+		n, _ = server.Read(buf)
+		received = append(received, string(buf[:n]))
+		server.Write([]byte("\r\nDone\r\nR1#"))
+
+		answers <- received
+	}()
+
+	stdout, err := tc.Execute("delete", "flash:old.bin")
+	if err != nil {
+		t.Fatalf("AutoConfirm: unexpected error: %v", err)
+	}
+
+	want := []byte("Delete flash:old.bin? (y/n) y\r\n" +
+		"Proceed with reload? [confirm]\r\nDone\r\n")
+	if bytes.Compare(stdout, want) != 0 {
+		t.Errorf(
+			"AutoConfirm: wrong output:\n\t\tfact = %q\n\t\twant = %q",
+			stdout, want)
+	}
+
+	wantAnswers := []string{"delete flash:old.bin\r\n", "y\r\n", "\r\n"}
+	if received := <-answers; !reflect.DeepEqual(received, wantAnswers) {
+		t.Errorf(
+			"AutoConfirm: wrong answers:\n\t\tfact = %q\n\t\twant = %q",
+			received, wantAnswers)
+	}
+}
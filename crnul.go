@@ -0,0 +1,61 @@
+package telnet
+
+import "bytes"
+
+// skipCRNul reports whether byte is NUL following CR, which is dropped
+// from received data. RFC 854 requires bare CR to be sent as CR NUL,
+// unless TRANSMIT-BINARY is enabled
+func (tc *TelnetClient) skipCRNul(b byte) bool {
+	if b == 0 && tc.afterCR && !tc.RemoteEnabled(OptBinary) {
+		tc.afterCR = false
+		return true
+	}
+	tc.afterCR = b == '\r'
+
+	return false
+}
+
+// outgoingCR escapes CR of sent data, unless TRANSMIT-BINARY is enabled
+func (tc *TelnetClient) outgoingCR(data []byte) []byte {
+	if tc.LocalEnabled(OptBinary) {
+		return data
+	}
+
+	return escapeCR(data)
+}
+
+// escapeAllCR appends NUL to every CR, so CR LF of binary data
+// isn't taken as end of line in NVT mode
+func escapeAllCR(data []byte) []byte {
+	if bytes.IndexByte(data, '\r') == -1 {
+		return data
+	}
+
+	escaped := make([]byte, 0, len(data)+1)
+	for _, b := range data {
+		escaped = append(escaped, b)
+		if b == '\r' {
+			escaped = append(escaped, 0)
+		}
+	}
+
+	return escaped
+}
+
+// escapeCR appends NUL to CR bytes, which aren't followed by LF,
+// so data is sent as RFC 854 requires
+func escapeCR(data []byte) []byte {
+	if bytes.IndexByte(data, '\r') == -1 {
+		return data
+	}
+
+	escaped := make([]byte, 0, len(data)+1)
+	for i, b := range data {
+		escaped = append(escaped, b)
+		if b == '\r' && (i+1 == len(data) || data[i+1] != '\n') {
+			escaped = append(escaped, 0)
+		}
+	}
+
+	return escaped
+}
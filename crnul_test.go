@@ -0,0 +1,97 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func Test_TelnetClient_skipCRNul(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		binary bool
+		want   string
+	}{
+		{"CR NUL", "Progress: 10%\r\x0020%\r\x00done\r\n", false, "Progress: 10%\r20%\rdone\r\n"},
+		{"NUL without CR", "a\x00b\r\n", false, "a\x00b\r\n"},
+		{"command between CR and NUL", "a\r\xff\xf1\x00b\r\n", false, "a\rb\r\n"},
+		{"binary mode", "a\r\x00b\r\n", true, "a\r\x00b\r\n"},
+	}
+
+	for _, tt := range tests {
+		tc := &TelnetClient{
+			reader: bufio.NewReader(bytes.NewBufferString(tt.data)),
+		}
+		if tt.binary {
+			tc.acceptRemote(OptBinary, nil)
+			tc.remoteOptions[OptBinary].enabled = true
+		}
+
+		var output []byte
+		if _, err := tc.ReadUntil(&output, '\n'); err != nil {
+			t.Fatalf("[%s] skipCRNul: unexpected error: %v", tt.name, err)
+		}
+		if string(output) != tt.want {
+			t.Errorf("[%s] skipCRNul: wrong output:\n\t\tfact = %q\n\t\twant = %q",
+				tt.name, output, tt.want)
+		}
+	}
+}
+
+func Test_escapeCR(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"show version\r\n", "show version\r\n"},
+		{"\r", "\r\x00"},
+		{"a\rb\r\n\r", "a\r\x00b\r\n\r\x00"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if escaped := escapeCR([]byte(tt.data)); string(escaped) != tt.want {
+			t.Errorf("escapeCR: wrong result of %q:\n\t\tfact = %q\n\t\twant = %q",
+				tt.data, escaped, tt.want)
+		}
+	}
+}
+
+func Test_TelnetClient_EnableBinary(t *testing.T) {
+	tests := []struct {
+		name       string
+		enable     bool
+		wantSent   []byte
+		wantOutput string
+		wantCR     string
+	}{
+		{"accepted", true, []byte{0xff, 0xfd, 0x00, 0xff, 0xfb, 0x00}, "a\r\x00b\r\n", "x\ry\r\n"},
+		{"refused", false, nil, "a\rb\r\n", "x\r\x00y\r\n"},
+	}
+
+	for _, tt := range tests {
+		sent := &bytes.Buffer{}
+		tc := &TelnetClient{
+			EnableBinary: tt.enable,
+			writer:       bufio.NewWriter(sent),
+		}
+		tc.reader = bufio.NewReader(bytes.NewBufferString(
+			"\xff\xfb\x00\xff\xfd\x00a\r\x00b\r\n"))
+		tc.setupOptions()
+
+		var output []byte
+		if _, err := tc.ReadUntil(&output, '\n'); err != nil {
+			t.Fatalf("[%s] EnableBinary: unexpected error: %v", tt.name, err)
+		}
+		if string(output) != tt.wantOutput {
+			t.Errorf("[%s] EnableBinary: wrong output: %q", tt.name, output)
+		}
+		if !bytes.Equal(sent.Bytes(), tt.wantSent) {
+			t.Errorf("[%s] EnableBinary: wrong answers: % x", tt.name, sent.Bytes())
+		}
+		if data := tc.outgoingCR([]byte("x\ry\r\n")); string(data) != tt.wantCR {
+			t.Errorf("[%s] EnableBinary: wrong sent data: %q", tt.name, data)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package telnet
+
+// defaultMaxSubnegotiation is a default limit of subnegotiation payload
+const defaultMaxSubnegotiation = 64 * 1024
+
+// decoderState is a position of ProtocolDecoder inside of telnet sequence
+type decoderState int
+
+const (
+	decodeData decoderState = iota
+	decodeIAC
+	decodeOption
+	decodeSB
+	decodeSBIAC
+)
+
+// Event is a telnet command found by ProtocolDecoder
+type Event struct {
+	// Offset is a number of data bytes returned by Decode before the event
+	Offset int
+	// Command is WILL, WONT, DO, DONT with Option, SB with Option
+	// and Data, or a command without parameters (e.g. NOP, GA)
+	Command Command
+	Option  Option
+	// Data is subnegotiation payload with IAC IAC unescaped
+	Data []byte
+	// Invalid marks unknown commands, commands not allowed inside
+	// of subnegotiation and subnegotiations, which are empty
+	// or exceed MaxSubnegotiation
+	Invalid bool
+}
+
+// ProtocolDecoder separates data from telnet commands. It keeps state
+// between calls, so sequences split across reads are decoded correctly.
+// Decoding never fails: malformed sequences are reported as invalid events
+type ProtocolDecoder struct {
+	// MaxSubnegotiation limits payload of subnegotiation (64KB by default),
+	// so SB without SE doesn't swallow the rest of the stream
+	MaxSubnegotiation int
+
+	state   decoderState
+	command Command
+	// payload of subnegotiation starts with option code
+	payload []byte
+}
+
+// Decode returns data bytes of input and events found in it.
+// Incomplete sequence at the end of input is kept until the next call
+func (d *ProtocolDecoder) Decode(input []byte) (data []byte, events []Event) {
+	for _, b := range input {
+		isData, event := d.DecodeByte(b)
+		if isData {
+			data = append(data, b)
+		}
+		if event != nil {
+			event.Offset = len(data)
+			events = append(events, *event)
+		}
+	}
+
+	return
+}
+
+// DecodeByte decodes the next byte of stream. It reports whether
+// byte is data and returns event completed by the byte
+func (d *ProtocolDecoder) DecodeByte(b byte) (isData bool, event *Event) {
+	switch d.state {
+	case decodeData:
+		if Command(b) == IAC {
+			d.state = decodeIAC
+			return false, nil
+		}
+		return true, nil
+
+	case decodeIAC:
+		d.state = decodeData
+		switch cmd := Command(b); {
+		case cmd == IAC:
+			// escaped 0xff is data
+			return true, nil
+		case cmd == WILL || cmd == WONT || cmd == DO || cmd == DONT:
+			d.command = cmd
+			d.state = decodeOption
+			return false, nil
+		case cmd == SB:
+			d.state = decodeSB
+			d.payload = nil
+			return false, nil
+		case cmd < CmdEOF:
+			return false, &Event{Command: cmd, Invalid: true}
+		default:
+			return false, &Event{Command: cmd}
+		}
+
+	case decodeOption:
+		d.state = decodeData
+		return false, &Event{Command: d.command, Option: Option(b)}
+
+	case decodeSB:
+		if Command(b) == IAC {
+			d.state = decodeSBIAC
+			return false, nil
+		}
+		return false, d.appendPayload(b)
+
+	case decodeSBIAC:
+		d.state = decodeSB
+		switch Command(b) {
+		case SE:
+			d.state = decodeData
+			payload := d.payload
+			d.payload = nil
+			if len(payload) == 0 {
+				// IAC SB IAC SE has no option
+				return false, &Event{Command: SB, Invalid: true}
+			}
+			return false, &Event{Command: SB, Option: Option(payload[0]), Data: payload[1:]}
+		case IAC:
+			return false, d.appendPayload(b)
+		default:
+			// other commands aren't allowed inside of subnegotiation
+			return false, &Event{Command: Command(b), Invalid: true}
+		}
+	}
+
+	return
+}
+
+// appendPayload adds byte to subnegotiation payload. Subnegotiation
+// exceeding MaxSubnegotiation is dropped and decoder returns to data
+func (d *ProtocolDecoder) appendPayload(b byte) *Event {
+	limit := d.MaxSubnegotiation
+	if limit <= 0 {
+		limit = defaultMaxSubnegotiation
+	}
+	if len(d.payload) > limit {
+		d.state = decodeData
+		event := &Event{Command: SB, Option: Option(d.payload[0]), Invalid: true}
+		d.payload = nil
+		return event
+	}
+
+	d.payload = append(d.payload, b)
+
+	return nil
+}
+
+// hasData reports whether input contains data bytes.
+// State of decoder isn't changed, as it is a copy
+func (d ProtocolDecoder) hasData(input []byte) bool {
+	for _, b := range input {
+		if isData, _ := d.DecodeByte(b); isData {
+			return true
+		}
+	}
+
+	return false
+}
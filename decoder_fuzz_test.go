@@ -0,0 +1,52 @@
+//go:build go1.18
+// +build go1.18
+
+package telnet
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzProtocolDecoder(f *testing.F) {
+	for _, seed := range []string{
+		"R1#",
+		"\xff",
+		"\xff\xff",
+		"\xff\xfb\x01\xff\xfd\x18",
+		"\xff\xfa\x18\x01\xff\xf0",
+		"\xff\xfa\x18\x01",
+		"\xff\xfa\xff\xf0",
+		"\xff\xfa\xff\xff\xff\xf1\xff\xf0",
+		"\xff\x10\xff\xf9",
+	} {
+		f.Add([]byte(seed), 1)
+	}
+
+	f.Fuzz(func(t *testing.T, input []byte, split int) {
+		d := &ProtocolDecoder{MaxSubnegotiation: 16}
+		data, events := d.Decode(input)
+		if len(data) > len(input) {
+			t.Fatalf("data is longer than input: %d > %d", len(data), len(input))
+		}
+		for _, event := range events {
+			if event.Offset < 0 || event.Offset > len(data) || len(event.Data) > 16 {
+				t.Fatalf("wrong event %+v", event)
+			}
+		}
+
+		if split < 0 || split > len(input) {
+			return
+		}
+		splitData, splitEvents := decodeSplit(&ProtocolDecoder{MaxSubnegotiation: 16}, input, split)
+		if !bytes.Equal(splitData, data) || !sameEvents(splitEvents, events) {
+			t.Fatalf("split at %d changes result", split)
+		}
+
+		// escaped data is decoded back without events
+		escaped, escapedEvents := (&ProtocolDecoder{}).Decode(escapeIAC(data))
+		if !bytes.Equal(escaped, data) || len(escapedEvents) != 0 {
+			t.Fatalf("escaped data %q isn't decoded back", data)
+		}
+	})
+}
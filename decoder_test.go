@@ -0,0 +1,127 @@
+package telnet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func Test_ProtocolDecoder(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantData   string
+		wantEvents []Event
+	}{
+		{
+			name:     "negotiation",
+			input:    "\xff\xfb\x01R1#\xff\xf9",
+			wantData: "R1#",
+			wantEvents: []Event{
+				{Offset: 0, Command: WILL, Option: OptEcho},
+				{Offset: 3, Command: CmdGA},
+			},
+		},
+		{
+			name:     "IAC IAC is data",
+			input:    "a\xff\xffb",
+			wantData: "a\xffb",
+		},
+		{
+			name:     "subnegotiation",
+			input:    "\xff\xfa\x18\x01\xff\xff\xff\xf0ok",
+			wantData: "ok",
+			wantEvents: []Event{
+				{Command: SB, Option: OptTerminalType, Data: []byte{0x01, 0xff}},
+			},
+		},
+		{
+			name:     "command inside of subnegotiation",
+			input:    "\xff\xfa\x18\x01\xff\xf1\xff\xf0",
+			wantData: "",
+			wantEvents: []Event{
+				{Command: CmdNOP, Invalid: true},
+				{Command: SB, Option: OptTerminalType, Data: []byte{0x01}},
+			},
+		},
+		{
+			name:     "empty subnegotiation",
+			input:    "\xff\xfa\xff\xf0",
+			wantData: "",
+			wantEvents: []Event{
+				{Command: SB, Invalid: true},
+			},
+		},
+		{
+			name:     "unknown command",
+			input:    "\xff\x10a",
+			wantData: "a",
+			wantEvents: []Event{
+				{Command: Command(0x10), Invalid: true},
+			},
+		},
+		{
+			name:     "subnegotiation without SE",
+			input:    "\xff\xfa\x18" + string(bytes.Repeat([]byte("x"), 9)) + "data",
+			wantData: "ta",
+			wantEvents: []Event{
+				{Command: SB, Option: OptTerminalType, Invalid: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		d := &ProtocolDecoder{MaxSubnegotiation: 10}
+		data, events := d.Decode([]byte(tt.input))
+		if string(data) != tt.wantData {
+			t.Errorf("[%s] ProtocolDecoder: wrong data:\n\t\tfact = %q\n\t\twant = %q",
+				tt.name, data, tt.wantData)
+		}
+		if !reflect.DeepEqual(events, tt.wantEvents) {
+			t.Errorf("[%s] ProtocolDecoder: wrong events:\n\t\tfact = %+v\n\t\twant = %+v",
+				tt.name, events, tt.wantEvents)
+		}
+	}
+}
+
+func Test_ProtocolDecoder_split(t *testing.T) {
+	input := []byte("a\xff\xffb\xff\xfd\x1fc\xff\xfa\x18\x01\xff\xf0d\xff")
+	whole, wholeEvents := (&ProtocolDecoder{}).Decode(input)
+
+	for i := range input {
+		data, events := decodeSplit(&ProtocolDecoder{}, input, i)
+		if !bytes.Equal(data, whole) || !sameEvents(events, wholeEvents) {
+			t.Errorf("ProtocolDecoder: split at %d changes result: %q, %+v", i, data, events)
+		}
+	}
+}
+
+// decodeSplit decodes input in two parts split at i
+// with offsets of events relative to the whole data
+func decodeSplit(d *ProtocolDecoder, input []byte, i int) (data []byte, events []Event) {
+	data, events = d.Decode(input[:i])
+
+	tail, tailEvents := d.Decode(input[i:])
+	for _, event := range tailEvents {
+		event.Offset += len(data)
+		events = append(events, event)
+	}
+
+	return append(data, tail...), events
+}
+
+// sameEvents compares events, nil and empty payloads are equal
+func sameEvents(a, b []Event) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Offset != b[i].Offset || a[i].Command != b[i].Command ||
+			a[i].Option != b[i].Option || a[i].Invalid != b[i].Invalid ||
+			!bytes.Equal(a[i].Data, b[i].Data) {
+			return false
+		}
+	}
+
+	return true
+}
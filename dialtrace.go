@@ -0,0 +1,84 @@
+package telnet
+
+import (
+	"context"
+	"net"
+)
+
+// DialTrace is a set of hooks called at stages of Dial, e.g. to measure
+// where slow connections spend their time. Any hook may be nil
+type DialTrace struct {
+	// DNSStart and DNSDone are called around resolving of host name.
+	// They aren't called for IP addresses
+	DNSStart func(host string)
+	DNSDone  func(addrs []string, err error)
+	// ConnectStart and ConnectDone are called around TCP connect
+	// of each address
+	ConnectStart func(address string)
+	ConnectDone  func(address string, err error)
+	// NegotiationDone is called, when the first prompt (login, password
+	// or banner) is received, i.e. server has finished option negotiation
+	NegotiationDone func()
+	// LoginDone is called, when login is completed or failed
+	LoginDone func(err error)
+}
+
+// WithDialTrace makes Dial call hooks of trace
+func WithDialTrace(trace *DialTrace) DialOption {
+	return func(options *dialOptions) {
+		options.trace = trace
+	}
+}
+
+// dialAddress connects to host:port address. If trace has DNS hooks,
+// host name is resolved explicitly and resolved addresses are tried in order
+func (tc *TelnetClient) dialAddress(address string) (conn net.Conn, err error) {
+	trace := tc.dialTrace
+	if trace == nil {
+		trace = &DialTrace{}
+	}
+
+	addresses := []string{address}
+	host, port, _ := net.SplitHostPort(address)
+	if (trace.DNSStart != nil || trace.DNSDone != nil) && net.ParseIP(host) == nil {
+		var addrs []string
+
+		if trace.DNSStart != nil {
+			trace.DNSStart(host)
+		}
+		ctx := context.Background()
+		if tc.ConnTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, tc.ConnTimeout)
+			defer cancel()
+		}
+		addrs, err = net.DefaultResolver.LookupHost(ctx, host)
+		if trace.DNSDone != nil {
+			trace.DNSDone(addrs, err)
+		}
+		if err != nil {
+			return
+		}
+
+		addresses = addresses[:0]
+		for _, addr := range addrs {
+			addresses = append(addresses, net.JoinHostPort(addr, port))
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: tc.ConnTimeout}
+	for _, address := range addresses {
+		if trace.ConnectStart != nil {
+			trace.ConnectStart(address)
+		}
+		conn, err = dialer.Dial("tcp", address)
+		if trace.ConnectDone != nil {
+			trace.ConnectDone(address, err)
+		}
+		if err == nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,81 @@
+package telnet
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_WithDialTrace(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("WithDialTrace: can't listen: %v", err)
+	}
+	defer ln.Close()
+
+	// server
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("R1 login: "))
+		conn.Read(make([]byte, 64))
+		conn.Write([]byte("admin@R1:~$ "))
+	}()
+
+	var events []string
+	trace := &DialTrace{
+		DNSStart: func(host string) {
+			events = append(events, "dns start "+host)
+		},
+		DNSDone: func(addrs []string, err error) {
+			events = append(events, "dns done")
+		},
+		ConnectStart: func(address string) {
+			events = append(events, "connect start")
+		},
+		ConnectDone: func(address string, err error) {
+			if err == nil {
+				events = append(events, "connect done")
+			}
+		},
+		NegotiationDone: func() {
+			events = append(events, "negotiation done")
+		},
+		LoginDone: func(err error) {
+			if err == nil {
+				events = append(events, "login done")
+			}
+		},
+	}
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	tc := &TelnetClient{
+		Address:     "localhost",
+		Port:        port,
+		Login:       "admin",
+		ReadTimeout: time.Second,
+	}
+	if err = tc.Dial(WithDialTrace(trace)); err != nil {
+		t.Fatalf("WithDialTrace: unexpected error: %v", err)
+	}
+	defer tc.Close()
+
+	// localhost may be resolved to several addresses, only one of them listens
+	want := []string{
+		"dns start localhost", "dns done",
+		"connect start", "connect done",
+		"negotiation done", "login done",
+	}
+	var fact []string
+	for _, event := range events {
+		if event != "connect start" || len(fact) == 0 || fact[len(fact)-1] != event {
+			fact = append(fact, event)
+		}
+	}
+	if !reflect.DeepEqual(fact, want) {
+		t.Errorf("WithDialTrace: wrong events:\n\t\tfact = %q\n\t\twant = %q", fact, want)
+	}
+}
@@ -0,0 +1,92 @@
+package telnet
+
+import (
+	"fmt"
+	"time"
+)
+
+// DiscardMode defines what Execute does with data received
+// before the command is sent, e.g. async notifications of device
+type DiscardMode int
+
+const (
+	// DiscardAuto passes data to OnUnsolicited, if it is set,
+	// otherwise data is dropped
+	DiscardAuto DiscardMode = iota
+	// DiscardDrop always drops data
+	DiscardDrop
+	// DiscardToHistory drops data after it is kept in History
+	// and copied to Tee writers
+	DiscardToHistory
+	// DiscardToUnsolicited passes data to OnUnsolicited,
+	// data is dropped, if it isn't set
+	DiscardToUnsolicited
+)
+
+func (m DiscardMode) String() string {
+	switch m {
+	case DiscardAuto:
+		return "Auto"
+	case DiscardDrop:
+		return "Drop"
+	case DiscardToHistory:
+		return "ToHistory"
+	case DiscardToUnsolicited:
+		return "ToUnsolicited"
+	}
+
+	return fmt.Sprintf("DiscardMode(%d)", int(m))
+}
+
+// ExecuteDiscarding is like Execute, but data received before
+// the command is handled according to mode instead of DiscardMode
+// of client. DiscardAuto keeps DiscardMode of client
+func (tc *TelnetClient) ExecuteDiscarding(
+	mode DiscardMode,
+	name string,
+	args ...string,
+) (stdout []byte, err error) {
+	tc.execMu.Lock()
+	defer tc.execMu.Unlock()
+	defer tc.markActive()
+
+	tc.callDiscardMode = mode
+	defer func() {
+		tc.callDiscardMode = DiscardAuto
+	}()
+
+	result, err := tc.executeResult(name, args...)
+	stdout = result.Output
+
+	return
+}
+
+// discardMode returns mode of the current call or of the client
+func (tc *TelnetClient) discardMode() DiscardMode {
+	if tc.callDiscardMode != DiscardAuto {
+		return tc.callDiscardMode
+	}
+
+	return tc.DiscardMode
+}
+
+// keepHistory reads received data, so it is kept in History,
+// and drops it. Reading doesn't wait for more data
+func (tc *TelnetClient) keepHistory() (err error) {
+	tc.setReadDeadline(time.Now())
+
+	for tc.buffered() > 0 {
+		_, err = tc.readByte()
+		if err == errRecordEnd {
+			continue
+		}
+		if isTimeout(err) {
+			break
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return tc.discardReceived()
+}
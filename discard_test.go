@@ -0,0 +1,82 @@
+package telnet
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_DiscardMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        DiscardMode
+		callMode    DiscardMode
+		wantLines   []string
+		wantHistory bool
+	}{
+		{"auto", DiscardAuto, DiscardAuto, []string{"%LINK-3-UPDOWN: down"}, true},
+		{"drop", DiscardDrop, DiscardAuto, nil, false},
+		{"history", DiscardToHistory, DiscardAuto, nil, true},
+		{"unsolicited", DiscardToUnsolicited, DiscardAuto, []string{"%LINK-3-UPDOWN: down"}, true},
+		{"call drop", DiscardToUnsolicited, DiscardDrop, nil, false},
+		{"call history", DiscardDrop, DiscardToHistory, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			var lines []string
+			tc := &TelnetClient{
+				ReadTimeout: time.Second,
+				Delimiter:   defaultDelimiter,
+				BannerRe:    defaultBannerRe,
+				HistorySize: 256,
+				DiscardMode: tt.mode,
+				OnUnsolicited: func(line []byte) {
+					lines = append(lines, string(line))
+				},
+				UnsolicitedWait: 20 * time.Millisecond,
+			}
+			tc.setConn(client)
+
+			// server
+			go func() {
+				server.Write([]byte("\r\n%LINK-3-UPDOWN: down"))
+
+				command := make([]byte, 64)
+				server.Read(command)
+				server.Write([]byte("up 3 days\r\nadmin@RT-N14U:/tmp/home/root# "))
+			}()
+
+			// let notification arrive before the command
+			time.Sleep(20 * time.Millisecond)
+			tc.reader.Peek(1)
+
+			stdout, err := tc.ExecuteDiscarding(tt.callMode, "uptime")
+			if err != nil {
+				t.Fatalf("DiscardMode: unexpected error: %v", err)
+			}
+
+			if want := []byte("up 3 days\r\n"); bytes.Compare(stdout, want) != 0 {
+				t.Errorf("DiscardMode: wrong output: %q", stdout)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("DiscardMode: wrong lines:\n\t\tfact = %q\n\t\twant = %q", lines, tt.wantLines)
+			}
+			inHistory := strings.Contains(string(tc.History()), "%LINK-3-UPDOWN: down")
+			if inHistory != tt.wantHistory {
+				t.Errorf("DiscardMode: notification is kept in history %v, want %v: %q",
+					inHistory, tt.wantHistory, tc.History())
+			}
+			if tc.callDiscardMode != DiscardAuto {
+				t.Errorf("DiscardMode: mode of call isn't reset: %v", tc.callDiscardMode)
+			}
+		})
+	}
+}
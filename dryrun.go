@@ -0,0 +1,58 @@
+package telnet
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// dryRunTransport replaces connection in DryRun mode,
+// reading of it ends with io.EOF
+type dryRunTransport struct{}
+
+func (dryRunTransport) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (dryRunTransport) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (dryRunTransport) Close() error {
+	return nil
+}
+
+func (dryRunTransport) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+// recordDryRun records command instead of sending it
+// and returns its canned output
+func (tc *TelnetClient) recordDryRun(command string) []byte {
+	command = strings.TrimSpace(command)
+	tc.log("Dry run, command isn't sent: %s", command)
+	tc.dryRunCommands = append(tc.dryRunCommands, command)
+
+	if tc.DryRunOutput == nil {
+		return []byte{}
+	}
+
+	return tc.DryRunOutput(command)
+}
+
+// DryRunCommands returns commands recorded in DryRun mode
+func (tc *TelnetClient) DryRunCommands() []string {
+	return tc.dryRunCommands
+}
+
+// recordDryRunData records data written in DryRun mode
+func (tc *TelnetClient) recordDryRunData(data []byte) {
+	tc.log("Dry run, %d bytes aren't sent", len(data))
+	tc.dryRunData = append(tc.dryRunData, data...)
+}
+
+// DryRunData returns raw data written in DryRun mode,
+// e.g. by Write or SendPaced
+func (tc *TelnetClient) DryRunData() []byte {
+	return tc.dryRunData
+}
@@ -0,0 +1,68 @@
+package telnet
+
+import (
+	"io"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_DryRun(t *testing.T) {
+	tc := &TelnetClient{
+		Address: "192.0.2.1",
+		DryRun:  true,
+		DryRunOutput: func(command string) []byte {
+			if command == "show clock" {
+				return []byte("12:00:00 UTC\r\n")
+			}
+			return nil
+		},
+	}
+	if err := tc.Dial(); err != nil {
+		t.Fatalf("DryRun: unexpected error of Dial: %v", err)
+	}
+	defer tc.Close()
+
+	output, err := tc.Execute("show", "clock")
+	if err != nil || string(output) != "12:00:00 UTC\r\n" {
+		t.Errorf("DryRun: unexpected result of Execute: %q, %v", output, err)
+	}
+	if err = tc.ExecuteNoWait("reload"); err != nil {
+		t.Errorf("DryRun: unexpected error of ExecuteNoWait: %v", err)
+	}
+
+	want := []string{"show clock", "reload"}
+	if !reflect.DeepEqual(tc.DryRunCommands(), want) {
+		t.Errorf("DryRun: wrong commands:\n\t\tfact = %q\n\t\twant = %q", tc.DryRunCommands(), want)
+	}
+}
+
+func Test_TelnetClient_DryRunSendPaced(t *testing.T) {
+	tc := &TelnetClient{Address: "192.0.2.1", DryRun: true}
+	if err := tc.Dial(); err != nil {
+		t.Fatalf("DryRun: unexpected error of Dial: %v", err)
+	}
+	defer tc.Close()
+
+	config := []byte("hostname R1\ninterface Gi0/1\n shutdown\n")
+	err := tc.SendPaced(config, PacingOptions{ChunkSize: 4, VerifyEcho: true})
+	if err != nil {
+		t.Fatalf("DryRun: unexpected error of SendPaced: %v", err)
+	}
+	if _, err = tc.Write([]byte("end\r\n")); err != nil {
+		t.Fatalf("DryRun: unexpected error of Write: %v", err)
+	}
+
+	want := "hostname R1\r\ninterface Gi0/1\r\n shutdown\r\nend\r\n"
+	if string(tc.DryRunData()) != want {
+		t.Errorf("DryRun: wrong data:\n\t\tfact = %q\n\t\twant = %q", tc.DryRunData(), want)
+	}
+
+	if _, _, err = tc.Expect(regexp.MustCompile("R1#")); err != io.EOF {
+		t.Errorf("DryRun: unexpected error of Expect: %v", err)
+	}
+	if output, err := tc.Drain(time.Millisecond); len(output) != 0 || err != io.EOF {
+		t.Errorf("DryRun: unexpected result of Drain: %q, %v", output, err)
+	}
+}
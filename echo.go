@@ -0,0 +1,87 @@
+package telnet
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// EchoMode defines how Execute handles command echoed by remote server
+type EchoMode int
+
+const (
+	// EchoNever means device doesn't echo commands, output is kept as is
+	EchoNever EchoMode = iota
+	// EchoAlways means the first line of output is always an echo
+	// and it is stripped
+	EchoAlways
+	// EchoAuto strips the first line of output,
+	// only if it matches the sent command
+	EchoAuto
+)
+
+func (m EchoMode) String() string {
+	switch m {
+	case EchoNever:
+		return "Never"
+	case EchoAlways:
+		return "Always"
+	case EchoAuto:
+		return "Auto"
+	}
+
+	return fmt.Sprintf("EchoMode(%d)", int(m))
+}
+
+// stripEcho removes the echoed command from the beginning of output
+func (tc *TelnetClient) stripEcho(output []byte, command string) []byte {
+	if tc.EchoMode == EchoNever {
+		return output
+	}
+
+	line, rest := output, output[len(output):]
+	if n := bytes.IndexByte(output, '\n'); n != -1 {
+		line, rest = output[:n], output[n+1:]
+	}
+
+	if tc.EchoMode == EchoAuto {
+		echo := bytes.TrimSpace(applyControlChars(line))
+		if !bytes.HasSuffix(echo, bytes.TrimSpace([]byte(command))) {
+			return output
+		}
+	}
+
+	tc.log("Strip echoed command")
+
+	return rest
+}
+
+// applyControlChars returns text as it looks on terminal:
+// backspaces erase previous characters, other control characters
+// and ANSI escape sequences are dropped
+func applyControlChars(line []byte) []byte {
+	text := make([]byte, 0, len(line))
+
+	for i := 0; i < len(line); i++ {
+		b := line[i]
+		switch {
+		case b == '\b' || b == 0x7f:
+			if len(text) > 0 {
+				text = text[:len(text)-1]
+			}
+		case b == 0x1b:
+			// skip CSI sequence up to final byte
+			if i+1 < len(line) && line[i+1] == '[' {
+				i++
+				for i+1 < len(line) && (line[i+1] < 0x40 || line[i+1] > 0x7e) {
+					i++
+				}
+				i++
+			}
+		case b < 0x20:
+		default:
+			text = append(text, b)
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,64 @@
+package telnet
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_TelnetClient_stripEcho(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    EchoMode
+		command string
+		output  []byte
+		want    []byte
+	}{
+		{
+			name:    "stripEcho: never",
+			mode:    EchoNever,
+			command: "arp -a",
+			output:  []byte("arp -a \r\n? (192.168.1.1) at 70:85:c2:6c:e8:a3\r\n"),
+			want:    []byte("arp -a \r\n? (192.168.1.1) at 70:85:c2:6c:e8:a3\r\n"),
+		},
+		{
+			name:    "stripEcho: always",
+			mode:    EchoAlways,
+			command: "arp -a",
+			output:  []byte("arp -\r\n? (192.168.1.1) at 70:85:c2:6c:e8:a3\r\n"),
+			want:    []byte("? (192.168.1.1) at 70:85:c2:6c:e8:a3\r\n"),
+		},
+		{
+			name:    "stripEcho: auto with control chars",
+			mode:    EchoAuto,
+			command: "arp -a",
+			output:  []byte("\x1b[Karp -x\ba \r\n? (192.168.1.1) at 70:85:c2:6c:e8:a3\r\n"),
+			want:    []byte("? (192.168.1.1) at 70:85:c2:6c:e8:a3\r\n"),
+		},
+		{
+			name:    "stripEcho: auto without echo",
+			mode:    EchoAuto,
+			command: "arp -a",
+			output:  []byte("? (192.168.1.1) at 70:85:c2:6c:e8:a3\r\n"),
+			want:    []byte("? (192.168.1.1) at 70:85:c2:6c:e8:a3\r\n"),
+		},
+		{
+			name:    "stripEcho: only echo",
+			mode:    EchoAuto,
+			command: "clear",
+			output:  []byte("clear "),
+			want:    []byte{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := &TelnetClient{EchoMode: tt.mode}
+			fact := tc.stripEcho(tt.output, tt.command)
+			if bytes.Compare(fact, tt.want) != 0 {
+				t.Errorf(
+					"[%s] wrong result:\n\t\tfact = %q\n\t\twant = %q",
+					tt.name, fact, tt.want)
+			}
+		})
+	}
+}
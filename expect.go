@@ -0,0 +1,158 @@
+package telnet
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// ErrExpectTimeout is returned by ExpectWithTimeout, when none
+// of patterns is matched within its timeout
+var ErrExpectTimeout = errors.New("telnet: no pattern matched within timeout")
+
+// Pattern is a branch of dialog handled by ExpectWithTimeout
+type Pattern struct {
+	Re *regexp.Regexp
+	// Timeout limits waiting for the pattern since the previous match.
+	// Expired pattern isn't matched anymore, so optional questions
+	// can have short timeouts. Zero means ReadTimeout
+	Timeout time.Duration
+	// Send is sent as is, when pattern is matched,
+	// so line ending has to be included, e.g. "y\r\n"
+	Send string
+	// Call is called with matched text after Send is sent
+	Call func(matched []byte) error
+	// Finish ends dialog, when pattern is matched
+	Finish bool
+}
+
+// Expect reads until one of regexps is matched and returns output
+// with the match and index of matched regexp
+func (tc *TelnetClient) Expect(res ...*regexp.Regexp) (output []byte, found int, err error) {
+	defer tc.use()()
+
+	tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+	output, _, err = tc.readUntilMatch(func(window []byte) (loc []int) {
+		found, loc = findFirst(window, res...)
+		return
+	})
+
+	return
+}
+
+// ExpectWithTimeout runs dialog: it waits for any of patterns and performs
+// action of matched one, until pattern with Finish is matched.
+// Output of the whole dialog is returned
+func (tc *TelnetClient) ExpectWithTimeout(patterns []Pattern) (output []byte, err error) {
+	defer tc.use()()
+
+	var data []byte
+	var matched []byte
+	var idx int
+
+	for {
+		data, idx, matched, err = tc.expectNext(patterns)
+		output = append(output, data...)
+		if err != nil {
+			return
+		}
+
+		pattern := patterns[idx]
+		tc.log("Expect matched %q", matched)
+
+		if pattern.Send != "" {
+			_, err = tc.write([]byte(pattern.Send))
+			if err != nil {
+				return
+			}
+		}
+		if pattern.Call != nil {
+			err = pattern.Call(matched)
+			if err != nil {
+				return
+			}
+		}
+		if pattern.Finish {
+			return
+		}
+	}
+}
+
+// expectNext reads until one of patterns, which aren't expired, is matched.
+// Data read before timeout of some pattern is scanned again
+// with the rest of patterns
+func (tc *TelnetClient) expectNext(
+	patterns []Pattern,
+) (output []byte, idx int, matched []byte, err error) {
+	var data []byte
+	var loc []int
+	var found int
+
+	start := time.Now()
+	expired := make([]bool, len(patterns))
+
+	for {
+		var active []int
+		var res []*regexp.Regexp
+		var deadline time.Time
+
+		for i, pattern := range patterns {
+			if expired[i] {
+				continue
+			}
+			active = append(active, i)
+			res = append(res, pattern.Re)
+
+			if timeout := tc.patternTimeout(pattern); timeout > 0 {
+				if d := start.Add(timeout); deadline.IsZero() || d.Before(deadline) {
+					deadline = d
+				}
+			}
+		}
+		if len(active) == 0 {
+			return output, -1, nil, ErrExpectTimeout
+		}
+		tc.setReadDeadline(deadline)
+
+		data, loc, err = tc.readUntilMatch(func(window []byte) (loc []int) {
+			found, loc = findFirst(window, res...)
+			return
+		})
+
+		if err != ErrStalled && isTimeout(err) {
+			left := 0
+			now := time.Now()
+			for _, i := range active {
+				timeout := tc.patternTimeout(patterns[i])
+				if timeout > 0 && !now.Before(start.Add(timeout)) {
+					expired[i] = true
+				} else {
+					left++
+				}
+			}
+			if left == 0 {
+				return append(output, data...), -1, nil, ErrExpectTimeout
+			}
+			tc.unread(data)
+			continue
+		}
+
+		output = append(output, data...)
+		if err != nil {
+			return output, -1, nil, err
+		}
+		// Unmatched record of EORPrompts isn't a match
+		if found >= 0 {
+			return output, active[found], data[loc[0]:loc[1]], nil
+		}
+	}
+}
+
+// patternTimeout returns timeout of pattern or ReadTimeout
+func (tc *TelnetClient) patternTimeout(pattern Pattern) time.Duration {
+	if pattern.Timeout > 0 {
+		return pattern.Timeout
+	}
+
+	return tc.ReadTimeout
+}
@@ -0,0 +1,116 @@
+package telnet
+
+import (
+	"bytes"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_ExpectWithTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		question   bool
+		prompt     bool
+		wantOutput string
+		wantSent   string
+		wantErr    error
+	}{
+		{
+			name:       "question is asked",
+			question:   true,
+			prompt:     true,
+			wantOutput: "Save config? [y/n]y\r\n[OK]\r\nR1#",
+			wantSent:   "y\r\n",
+		},
+		{
+			name:       "question isn't asked",
+			prompt:     true,
+			wantOutput: "[OK]\r\nR1#",
+		},
+		{
+			name:       "all patterns are expired",
+			wantOutput: "[OK]\r\n",
+			wantErr:    ErrExpectTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			tc := &TelnetClient{ReadTimeout: time.Second}
+			tc.setConn(client)
+
+			// server
+			question, prompt := tt.question, tt.prompt
+			sent := make(chan string, 1)
+			go func() {
+				var received []byte
+				if question {
+					server.Write([]byte("Save config? [y/n]"))
+					buf := make([]byte, 16)
+					n, _ := server.Read(buf)
+					received = buf[:n]
+					server.Write(received)
+				}
+				server.Write([]byte("[OK]\r\n"))
+				if prompt {
+					server.Write([]byte("R1#"))
+				}
+				sent <- string(received)
+			}()
+
+			var calls int
+			output, err := tc.ExpectWithTimeout([]Pattern{
+				{
+					Re:      regexp.MustCompile(`\[y/n\]`),
+					Timeout: 50 * time.Millisecond,
+					Send:    "y\r\n",
+				},
+				{
+					Re:      regexp.MustCompile("R1#"),
+					Timeout: 200 * time.Millisecond,
+					Call: func(matched []byte) error {
+						calls++
+						return nil
+					},
+					Finish: true,
+				},
+			})
+
+			if err != tt.wantErr {
+				t.Errorf("[%s] ExpectWithTimeout: unexpected error: %v", tt.name, err)
+			}
+			if !bytes.Equal(output, []byte(tt.wantOutput)) {
+				t.Errorf("[%s] ExpectWithTimeout: wrong output:\n\t\tfact = %q\n\t\twant = %q",
+					tt.name, output, tt.wantOutput)
+			}
+			if s := <-sent; s != tt.wantSent {
+				t.Errorf("[%s] ExpectWithTimeout: wrong sent data: %q", tt.name, s)
+			}
+			if tt.prompt && calls != 1 {
+				t.Errorf("[%s] ExpectWithTimeout: Call is called %d times", tt.name, calls)
+			}
+		})
+	}
+}
+
+func Test_TelnetClient_Expect(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{ReadTimeout: time.Second}
+	tc.setConn(client)
+
+	go server.Write([]byte("Proceed? [confirm]"))
+
+	output, found, err := tc.Expect(regexp.MustCompile("R1#"), regexp.MustCompile(`\[confirm\]`))
+	if err != nil || found != 1 || string(output) != "Proceed? [confirm]" {
+		t.Errorf("Expect: unexpected result: %q, %d, %v", output, found, err)
+	}
+}
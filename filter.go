@@ -0,0 +1,161 @@
+package telnet
+
+// ReadFilter transforms incoming data after telnet commands are removed,
+// but before prompts are matched and output is returned.
+// Data comes by chunks, so filter has to keep incomplete sequences
+// until the next call. Filters are applied in the order of Filters field
+type ReadFilter func(data []byte) []byte
+
+// Transformer converts text between charsets.
+// It is compatible with golang.org/x/text/transform.Transformer,
+// so any of golang.org/x/text/encoding decoders can be used
+type Transformer interface {
+	Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error)
+	Reset()
+}
+
+// StripANSI returns filter, which removes ANSI escape sequences
+// (colors, cursor movements, window titles)
+func StripANSI() ReadFilter {
+	var pending []byte
+
+	return func(data []byte) []byte {
+		if len(pending) > 0 {
+			data = append(pending, data...)
+			pending = nil
+		}
+
+		result := make([]byte, 0, len(data))
+		for i := 0; i < len(data); i++ {
+			if data[i] != 0x1b {
+				result = append(result, data[i])
+				continue
+			}
+
+			n := escapeSequenceLen(data[i:])
+			if n == 0 {
+				pending = append(pending, data[i:]...)
+				break
+			}
+			i += n - 1
+		}
+
+		return result
+	}
+}
+
+// escapeSequenceLen returns length of escape sequence in the beginning
+// of data or zero, if the sequence is incomplete
+func escapeSequenceLen(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+
+	switch data[1] {
+	case '[':
+		// CSI: parameters and final byte in range 0x40-0x7e
+		for i := 2; i < len(data); i++ {
+			if data[i] >= 0x40 && data[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']':
+		// OSC: terminated by BEL or ESC \
+		for i := 2; i < len(data); i++ {
+			if data[i] == 0x07 {
+				return i + 1
+			}
+			if data[i] == 0x1b && i+1 < len(data) && data[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	default:
+		return 2
+	}
+
+	return 0
+}
+
+// NormalizeCRLF returns filter, which replaces CR LF line endings with LF
+func NormalizeCRLF() ReadFilter {
+	pendingCR := false
+
+	return func(data []byte) []byte {
+		result := make([]byte, 0, len(data)+1)
+		if pendingCR {
+			if len(data) == 0 || data[0] != '\n' {
+				result = append(result, '\r')
+			}
+			pendingCR = false
+		}
+
+		for i, b := range data {
+			if b != '\r' {
+				result = append(result, b)
+				continue
+			}
+
+			if i == len(data)-1 {
+				pendingCR = true
+			} else if data[i+1] != '\n' {
+				result = append(result, b)
+			}
+		}
+
+		return result
+	}
+}
+
+// maxIncompleteChar is the longest incomplete multibyte character,
+// which is kept until the next chunk (e.g. GB18030 four byte sequence)
+const maxIncompleteChar = 4
+
+// replacementChar replaces bytes, which transformer can't decode
+var replacementChar = []byte("\uFFFD")
+
+// Decoder returns filter, which converts incoming text to UTF-8
+// by the charset transformer, e.g. charmap.Windows1251.NewDecoder().
+// Bytes, which transformer fails on far from the end of data,
+// are invalid, they are replaced by U+FFFD
+func Decoder(t Transformer) ReadFilter {
+	var pending []byte
+
+	return func(data []byte) []byte {
+		if len(pending) > 0 {
+			data = append(pending, data...)
+			pending = nil
+		}
+
+		// single byte charsets need up to 3 bytes per character in UTF-8
+		dst := make([]byte, 4*len(data)+16)
+		var result []byte
+		for len(data) > 0 {
+			nDst, nSrc, err := t.Transform(dst, data, false)
+			result = append(result, dst[:nDst]...)
+			data = data[nSrc:]
+
+			switch {
+			case err == nil:
+				return result
+			case len(data) < maxIncompleteChar:
+				// incomplete multibyte character is kept until the next chunk
+				pending = append(pending, data...)
+				return result
+			case nSrc == 0:
+				result = append(result, replacementChar...)
+				data = data[1:]
+			}
+		}
+
+		return result
+	}
+}
+
+// applyFilters runs data through the filters chain
+func (tc *TelnetClient) applyFilters(data []byte) []byte {
+	for _, f := range tc.Filters {
+		data = f(data)
+	}
+
+	return data
+}
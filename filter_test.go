@@ -0,0 +1,160 @@
+package telnet
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// latin1Decoder converts ISO 8859-1 to UTF-8
+type latin1Decoder struct{}
+
+func (latin1Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for _, b := range src {
+		if nDst+utf8.RuneLen(rune(b)) > len(dst) {
+			return nDst, nSrc, errors.New("short destination")
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], rune(b))
+		nSrc++
+	}
+
+	return
+}
+
+func (latin1Decoder) Reset() {}
+
+// strictUTF8Decoder passes valid UTF-8 and fails on invalid bytes
+type strictUTF8Decoder struct{}
+
+func (strictUTF8Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && size <= 1 {
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, errors.New("short source")
+			}
+			return nDst, nSrc, errors.New("invalid byte")
+		}
+		if nDst+size > len(dst) {
+			return nDst, nSrc, errors.New("short destination")
+		}
+		nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+		nSrc += size
+	}
+
+	return
+}
+
+func (strictUTF8Decoder) Reset() {}
+
+func Test_ReadFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter ReadFilter
+		args   [][]byte
+		want   []byte
+	}{
+		{
+			name:   "StripANSI: colors",
+			filter: StripANSI(),
+			args: [][]byte{
+				[]byte("\x1b[1;32mOK\x1b[0m done\r\n"),
+			},
+			want: []byte("OK done\r\n"),
+		},
+		{
+			name:   "StripANSI: sequences split between chunks",
+			filter: StripANSI(),
+			args: [][]byte{
+				[]byte("\x1b]0;root@host\x07ab\x1b"),
+				[]byte("[2"),
+				[]byte("Kc\x1b"),
+				[]byte("=d"),
+			},
+			want: []byte("abcd"),
+		},
+		{
+			name:   "NormalizeCRLF: line endings",
+			filter: NormalizeCRLF(),
+			args: [][]byte{
+				[]byte("one\r\ntwo\r"),
+				[]byte("\nthree\r"),
+				[]byte("four"),
+			},
+			want: []byte("one\ntwo\nthree\rfour"),
+		},
+		{
+			name:   "Decoder: latin1",
+			filter: Decoder(latin1Decoder{}),
+			args: [][]byte{
+				{'c', 'a', 'f', 0xe9},
+			},
+			want: []byte("café"),
+		},
+		{
+			name:   "Decoder: character split between chunks",
+			filter: Decoder(strictUTF8Decoder{}),
+			args: [][]byte{
+				[]byte("caf\xc3"),
+				[]byte("\xa9 ok"),
+			},
+			want: []byte("café ok"),
+		},
+		{
+			name:   "Decoder: invalid bytes",
+			filter: Decoder(strictUTF8Decoder{}),
+			args: [][]byte{
+				[]byte("R1\xff\xfe> show"),
+				[]byte(" version\xff"),
+				[]byte("\r\nR1> "),
+			},
+			want: []byte("R1\uFFFD\uFFFD> show version\uFFFD\r\nR1> "),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fact := []byte{}
+			for _, a := range tt.args {
+				fact = append(fact, tt.filter(a)...)
+			}
+
+			if bytes.Compare(fact, tt.want) != 0 {
+				t.Errorf(
+					"[%s] wrong result:\n\t\tfact = %q\n\t\twant = %q",
+					tt.name, fact, tt.want)
+			}
+		})
+	}
+}
+
+func Test_TelnetClient_ReadUntilRegexp_filters(t *testing.T) {
+	tc := &TelnetClient{
+		ReadTimeout: 10 * time.Millisecond,
+		Delimiter:   defaultDelimiter,
+		Filters:     []ReadFilter{StripANSI(), NormalizeCRLF()},
+	}
+	tests := []testReadCase{
+		{
+			name: "ReadUntilRegexp: colored prompt",
+			args: [][]byte{
+				[]byte("\x1b[32mok\x1b[0m\r\n"),
+				[]byte("\x1b[1mroot@host\x1b[0m:~# "),
+			},
+			want: []byte("ok\nroot@host:~#"),
+		},
+	}
+	re := regexp.MustCompile("root@host:~#")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, tc, func() []byte {
+				output, _, _ := tc.ReadUntilRegexp(re)
+				return output
+			})
+		})
+	}
+}
@@ -0,0 +1,96 @@
+//go:build ignore
+// +build ignore
+
+// gen_names generates String methods of Command and Option
+// from constants of commands.go, run it with go generate
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+type constant struct {
+	ident string
+	name  string
+}
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "commands.go", nil, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	constants := map[string][]constant{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			typ, ok := value.Type.(*ast.Ident)
+			if !ok {
+				continue
+			}
+
+			for _, ident := range value.Names {
+				c := constant{ident: ident.Name}
+				switch typ.Name {
+				case "Command":
+					c.name = strings.TrimPrefix(ident.Name, "Cmd")
+				case "Option":
+					if value.Comment == nil {
+						log.Fatalf("option %s has no name comment", ident.Name)
+					}
+					c.name = strings.Split(value.Comment.Text(), ",")[0]
+				default:
+					continue
+				}
+				constants[typ.Name] = append(constants[typ.Name], c)
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "// Code generated by \"go run gen_names.go\"; DO NOT EDIT.")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "package telnet")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "import \"fmt\"")
+	writeStringer(&b, "Command", "c", constants["Command"])
+	writeStringer(&b, "Option", "o", constants["Option"])
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = ioutil.WriteFile("commands_string.go", source, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeStringer writes map of names and String method of type
+func writeStringer(b *bytes.Buffer, typ, receiver string, constants []constant) {
+	names := strings.ToLower(typ[:1]) + typ[1:] + "Names"
+
+	fmt.Fprintf(b, "\nvar %s = map[%s]string{\n", names, typ)
+	for _, c := range constants {
+		fmt.Fprintf(b, "%s: %q,\n", c.ident, c.name)
+	}
+	fmt.Fprintln(b, "}")
+
+	fmt.Fprintf(b, "\nfunc (%s %s) String() string {\n", receiver, typ)
+	fmt.Fprintf(b, "if name, ok := %s[%s]; ok {\nreturn name\n}\n\n", names, receiver)
+	fmt.Fprintf(b, "return fmt.Sprintf(\"%s(%%d)\", byte(%s))\n}\n", typ, receiver)
+}
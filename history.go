@@ -0,0 +1,65 @@
+package telnet
+
+// ringBuffer keeps the last written bytes up to its size
+type ringBuffer struct {
+	data []byte
+	pos  int
+	full bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{data: make([]byte, size)}
+}
+
+func (r *ringBuffer) WriteByte(b byte) error {
+	r.data[r.pos] = b
+	r.pos++
+	if r.pos == len(r.data) {
+		r.pos = 0
+		r.full = true
+	}
+
+	return nil
+}
+
+func (r *ringBuffer) Write(p []byte) (int, error) {
+	for _, b := range p {
+		r.WriteByte(b)
+	}
+
+	return len(p), nil
+}
+
+// Bytes returns copy of kept data in the order of writing
+func (r *ringBuffer) Bytes() []byte {
+	if !r.full {
+		return append([]byte{}, r.data[:r.pos]...)
+	}
+
+	result := make([]byte, 0, len(r.data))
+	result = append(result, r.data[r.pos:]...)
+
+	return append(result, r.data[:r.pos]...)
+}
+
+// recordHistory keeps received byte in scrollback buffer
+func (tc *TelnetClient) recordHistory(b byte) {
+	if tc.HistorySize <= 0 {
+		return
+	}
+	if tc.history == nil {
+		tc.history = newRingBuffer(tc.HistorySize)
+	}
+
+	tc.history.WriteByte(b)
+}
+
+// History returns the last HistorySize bytes of session output,
+// e.g. to dump recent context, when something goes wrong
+func (tc *TelnetClient) History() []byte {
+	if tc.history == nil {
+		return nil
+	}
+
+	return tc.history.Bytes()
+}
@@ -0,0 +1,61 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_ringBuffer(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		args []string
+		want []byte
+	}{
+		{
+			name: "ringBuffer: not full",
+			size: 8,
+			args: []string{"abc", "de"},
+			want: []byte("abcde"),
+		},
+		{
+			name: "ringBuffer: overwritten",
+			size: 4,
+			args: []string{"abc", "def", "g"},
+			want: []byte("defg"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRingBuffer(tt.size)
+			for _, a := range tt.args {
+				r.Write([]byte(a))
+			}
+
+			if fact := r.Bytes(); bytes.Compare(fact, tt.want) != 0 {
+				t.Errorf("[%s] wrong result: fact = %q, want = %q", tt.name, fact, tt.want)
+			}
+		})
+	}
+}
+
+func Test_TelnetClient_History(t *testing.T) {
+	tc := &TelnetClient{
+		HistorySize: 16,
+		reader: bufio.NewReader(strings.NewReader(
+			"show version\r\nIOS 15.2\r\n\xff\xfd\x1fR1#")),
+	}
+
+	for {
+		if _, err := tc.ReadByte(); err != nil {
+			break
+		}
+	}
+
+	if want := []byte("n\r\nIOS 15.2\r\nR1#"); bytes.Compare(tc.History(), want) != 0 {
+		t.Errorf("History: fact = %q, want = %q", tc.History(), want)
+	}
+}
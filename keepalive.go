@@ -0,0 +1,139 @@
+package telnet
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Healthy reports whether the last keepalive command succeeded,
+// so pools can drop broken sessions
+func (tc *TelnetClient) Healthy() bool {
+	return atomic.LoadInt32(&tc.unhealthy) == 0 && !tc.isClosed()
+}
+
+// markActive remembers time of the last command
+func (tc *TelnetClient) markActive() {
+	atomic.StoreInt64(&tc.lastActive, time.Now().UnixNano())
+}
+
+// use marks session as used by caller until returned function is called,
+// so keepalive doesn't interleave with reading or writing of caller.
+// It waits for running command or keepalive
+func (tc *TelnetClient) use() (release func()) {
+	tc.execMu.Lock()
+	tc.users++
+	tc.execMu.Unlock()
+	tc.markActive()
+
+	return func() {
+		tc.execMu.Lock()
+		tc.users--
+		tc.execMu.Unlock()
+		tc.markActive()
+	}
+}
+
+// idle returns time since the last command
+func (tc *TelnetClient) idle() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&tc.lastActive)))
+}
+
+// startKeepalive runs keepalive goroutine, previous one is stopped
+func (tc *TelnetClient) startKeepalive() {
+	tc.stopKeepalive()
+	tc.markActive()
+
+	tc.keepaliveMu.Lock()
+	stop := make(chan struct{})
+	tc.keepaliveStop = stop
+	tc.keepaliveMu.Unlock()
+
+	go tc.keepalive(stop)
+}
+
+// stopKeepalive stops keepalive goroutine, if it is running
+func (tc *TelnetClient) stopKeepalive() {
+	tc.keepaliveMu.Lock()
+	defer tc.keepaliveMu.Unlock()
+
+	if tc.keepaliveStop != nil {
+		close(tc.keepaliveStop)
+		tc.keepaliveStop = nil
+	}
+}
+
+// keepalive runs KeepaliveCommand, when session is idle
+// for KeepaliveInterval, until it is stopped or command fails
+func (tc *TelnetClient) keepalive(stop chan struct{}) {
+	interval := tc.KeepaliveInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+		}
+
+		if idle := tc.idle(); idle < interval {
+			timer.Reset(interval - idle)
+			continue
+		}
+
+		err := tc.probe(stop)
+		if err != nil {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			tc.log("Keepalive failed: %v", err)
+			atomic.StoreInt32(&tc.unhealthy, 1)
+			if tc.OnUnhealthy != nil {
+				tc.OnUnhealthy(err)
+			}
+			return
+		}
+		timer.Reset(interval)
+	}
+}
+
+// probe runs KeepaliveCommand or sends empty line and waits for prompt
+func (tc *TelnetClient) probe(stop chan struct{}) (err error) {
+	tc.execMu.Lock()
+	defer tc.execMu.Unlock()
+	defer tc.markActive()
+
+	// Session can be closed while waiting for running command
+	select {
+	case <-stop:
+		return nil
+	default:
+	}
+	// Session is read or written by another call, e.g. Expect or Shell
+	if tc.users > 0 {
+		return nil
+	}
+
+	if tc.KeepaliveCommand != "" {
+		_, err = tc.executeResult(tc.KeepaliveCommand)
+		return
+	}
+
+	err = tc.discardBuffered()
+	if err != nil {
+		return
+	}
+	tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+
+	tc.log("Send keepalive")
+	_, err = tc.write([]byte("\r\n"))
+	if err != nil {
+		return
+	}
+	_, _, err = tc.readUntilBannerConfirming()
+
+	return
+}
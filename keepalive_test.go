@@ -0,0 +1,133 @@
+package telnet
+
+import (
+	"net"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_Keepalive(t *testing.T) {
+	tests := []struct {
+		name        string
+		answer      bool
+		wantHealthy bool
+	}{
+		{name: "prompt is received", answer: true, wantHealthy: true},
+		{name: "prompt isn't received", answer: false, wantHealthy: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer server.Close()
+
+			failed := make(chan error, 1)
+			tc := &TelnetClient{
+				ReadTimeout:       50 * time.Millisecond,
+				BannerRe:          regexp.MustCompile("R1#"),
+				KeepaliveInterval: 20 * time.Millisecond,
+				KeepaliveCommand:  "show clock",
+				OnUnhealthy: func(err error) {
+					failed <- err
+				},
+			}
+			tc.setConn(client)
+
+			// server
+			answer := tt.answer
+			probes := make(chan string, 16)
+			go func() {
+				buf := make([]byte, 64)
+				for {
+					n, err := server.Read(buf)
+					if err != nil {
+						close(probes)
+						return
+					}
+					probes <- string(buf[:n])
+					if answer {
+						server.Write([]byte("12:00:00 UTC\r\nR1#"))
+					}
+				}
+			}()
+
+			tc.startKeepalive()
+
+			select {
+			case probe := <-probes:
+				if probe != "show clock \r\n" {
+					t.Errorf("[%s] Keepalive: wrong command %q", tt.name, probe)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("[%s] Keepalive: command isn't sent", tt.name)
+			}
+
+			if !tt.wantHealthy {
+				select {
+				case err := <-failed:
+					if err == nil {
+						t.Errorf("[%s] Keepalive: OnUnhealthy is called without error", tt.name)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("[%s] Keepalive: OnUnhealthy isn't called", tt.name)
+				}
+			} else {
+				// the second probe is sent after the first one succeeded
+				<-probes
+			}
+
+			if healthy := tc.Healthy(); healthy != tt.wantHealthy {
+				t.Errorf("[%s] Keepalive: unexpected health %v", tt.name, healthy)
+			}
+			tc.Close()
+		})
+	}
+}
+
+func Test_TelnetClient_KeepaliveExpect(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout:       time.Second,
+		BannerRe:          regexp.MustCompile("R1#"),
+		KeepaliveInterval: 10 * time.Millisecond,
+		KeepaliveCommand:  "show clock",
+	}
+	tc.setConn(client)
+
+	// server
+	received := make(chan string, 16)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := server.Read(buf)
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- string(buf[:n])
+		}
+	}()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		server.Write([]byte("Building configuration...\r\n[OK]\r\n"))
+	}()
+
+	tc.startKeepalive()
+	output, _, err := tc.Expect(regexp.MustCompile(`\[OK\]`))
+	if err != nil {
+		t.Fatalf("KeepaliveExpect: unexpected error: %v", err)
+	}
+	if want := "Building configuration...\r\n[OK]"; string(output) != want {
+		t.Errorf("KeepaliveExpect: wrong output %q", output)
+	}
+
+	select {
+	case data := <-received:
+		t.Errorf("KeepaliveExpect: keepalive is sent during Expect: %q", data)
+	default:
+	}
+	tc.Close()
+}
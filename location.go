@@ -0,0 +1,22 @@
+package telnet
+
+// sendLocation announces location, when SEND-LOCATION is enabled
+func (tc *TelnetClient) sendLocation() error {
+	tc.log("Send location %s", tc.Location)
+
+	return tc.sendSubnegotiation(OptSendLocation, []byte(tc.Location))
+}
+
+// sendXDisplay answers X-DISPLAY-LOCATION SEND request with
+// IAC SB X-DISPLAY-LOCATION IS display IAC SE
+func (tc *TelnetClient) sendXDisplay(data []byte) {
+	if len(data) == 0 || data[0] != sbSEND {
+		return
+	}
+
+	tc.log("Send X display location %s", tc.XDisplay)
+	err := tc.sendSubnegotiation(OptXDisplayLocation, append([]byte{sbIS}, tc.XDisplay...))
+	if err != nil {
+		tc.log("Failed to send X display location: %v", err)
+	}
+}
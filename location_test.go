@@ -0,0 +1,37 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func Test_TelnetClient_Location(t *testing.T) {
+	sent := &bytes.Buffer{}
+	tc := &TelnetClient{
+		Location: "Lab 5",
+		XDisplay: "ws12:0.0",
+		writer:   bufio.NewWriter(sent),
+	}
+	tc.reader = bufio.NewReader(bytes.NewReader([]byte{
+		0xff, 0xfd, 0x17,
+		0xff, 0xfd, 0x23,
+		0xff, 0xfa, 0x23, 0x01, 0xff, 0xf0,
+		'$',
+	}))
+	tc.setupOptions()
+
+	b, err := tc.ReadByte()
+	if err != nil || b != '$' {
+		t.Fatalf("Location: unexpected result of ReadByte: %v, %v", b, err)
+	}
+
+	want := append([]byte{0xff, 0xfb, 0x17, 0xff, 0xfa, 0x17}, "Lab 5"...)
+	want = append(want, 0xff, 0xf0, 0xff, 0xfb, 0x23, 0xff, 0xfa, 0x23, 0x00)
+	want = append(want, "ws12:0.0"...)
+	want = append(want, 0xff, 0xf0)
+	if bytes.Compare(sent.Bytes(), want) != 0 {
+		t.Errorf("Location: wrong answers:\n\t\tfact = % x\n\t\twant = % x",
+			sent.Bytes(), want)
+	}
+}
@@ -0,0 +1,133 @@
+package telnet
+
+import (
+	"bufio"
+	"compress/zlib"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errEndOfCompression is sent by inflater along with the last decompressed data
+var errEndOfCompression = errors.New("telnet: end of compressed stream")
+
+// startCompression switches read path to zlib inflater.
+// Server starts compressed stream right after IAC SB COMPRESS2 IAC SE
+func (tc *TelnetClient) startCompression(_ []byte) {
+	tc.log("Start of compressed stream")
+	r := &inflateReader{
+		tc:      tc,
+		raw:     tc.reader,
+		results: make(chan readResult),
+		done:    make(chan struct{}),
+	}
+	tc.inflater = r
+	atomic.StoreInt32(&tc.inflating, 1)
+	go r.readLoop()
+	tc.reader = bufio.NewReader(r)
+}
+
+// stopCompression stops background inflater of the connection
+func (tc *TelnetClient) stopCompression() {
+	if tc.inflater != nil {
+		tc.inflater.once.Do(func() { close(tc.inflater.done) })
+	}
+	atomic.StoreInt32(&tc.inflating, 0)
+}
+
+// isInflating reports whether connection is read by background inflater
+func (tc *TelnetClient) isInflating() bool {
+	return atomic.LoadInt32(&tc.inflating) == 1
+}
+
+// inflateReader decompresses data until end of zlib stream,
+// then it passes raw data through. flate keeps the first error it gets,
+// so inflater reads connection in background without deadline
+// and Read applies deadline while waiting for results
+type inflateReader struct {
+	tc      *TelnetClient
+	raw     *bufio.Reader
+	results chan readResult
+	pending []byte
+	err     error
+	done    chan struct{}
+	once    sync.Once
+}
+
+func (r *inflateReader) Read(p []byte) (n int, err error) {
+	for len(r.pending) == 0 && r.err == nil {
+		err = r.wait()
+		if err != nil {
+			return
+		}
+	}
+
+	if len(r.pending) > 0 {
+		n = copy(p, r.pending)
+		r.pending = r.pending[n:]
+		r.tc.traceDump("recv", p[:n])
+		return
+	}
+
+	return 0, r.err
+}
+
+// wait waits for the next result of inflater until read deadline
+func (r *inflateReader) wait() error {
+	deadline, inactivity := r.tc.readDeadline()
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case result := <-r.results:
+		r.pending, r.err = result.data, result.err
+		if r.err == errEndOfCompression {
+			r.tc.log("End of compressed stream")
+			r.err = nil
+		}
+	case <-timeout:
+		if inactivity {
+			return ErrStalled
+		}
+		return timeoutError{}
+	}
+
+	return nil
+}
+
+func (r *inflateReader) readLoop() {
+	zr, err := zlib.NewReader(r.raw)
+	for {
+		buf := make([]byte, 4096)
+		n := 0
+		switch {
+		case err != nil:
+		case zr != nil:
+			n, err = zr.Read(buf)
+			if err == io.EOF {
+				zr.Close()
+				zr, err = nil, errEndOfCompression
+			}
+		default:
+			n, err = r.raw.Read(buf)
+		}
+
+		select {
+		case r.results <- readResult{data: buf[:n], err: err}:
+		case <-r.done:
+			return
+		}
+		if err == errEndOfCompression {
+			err = nil
+		} else if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_MCCP(t *testing.T) {
+	data := &bytes.Buffer{}
+	data.Write([]byte{0xff, 0xfb, 0x56, 0xff, 0xfa, 0x56, 0xff, 0xf0})
+
+	zw := zlib.NewWriter(data)
+	zw.Write([]byte("Welcome!\r\n"))
+	zw.Write([]byte{0xff, 0xfd, 0x1f})
+	zw.Write([]byte("> "))
+	zw.Close()
+
+	data.Write([]byte("uncompressed"))
+
+	sent := &bytes.Buffer{}
+	tc := &TelnetClient{
+		EnableMCCP: true,
+		reader:     bufio.NewReader(data),
+		writer:     bufio.NewWriter(sent),
+	}
+	tc.setupOptions()
+
+	output := []byte{}
+	for {
+		b, err := tc.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("MCCP: unexpected error: %v", err)
+		}
+		output = append(output, b)
+	}
+
+	if want := []byte("Welcome!\r\n> uncompressed"); bytes.Compare(output, want) != 0 {
+		t.Errorf("MCCP: wrong output:\n\t\tfact = %q\n\t\twant = %q", output, want)
+	}
+	if want := []byte{0xff, 0xfd, 0x56}; bytes.Compare(sent.Bytes(), want) != 0 {
+		t.Errorf("MCCP: wrong negotiation: %v", sent.Bytes())
+	}
+}
+
+func Test_TelnetClient_MCCPTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout: time.Second,
+		EnableMCCP:  true,
+	}
+	tc.setupOptions()
+	tc.setConn(client)
+	defer tc.Close()
+
+	next := make(chan struct{})
+	go io.Copy(ioutil.Discard, server)
+	go func() {
+		server.Write([]byte{0xff, 0xfb, 0x56, 0xff, 0xfa, 0x56, 0xff, 0xf0})
+		zw := zlib.NewWriter(server)
+		zw.Write([]byte("first\r\n"))
+		zw.Flush()
+		<-next
+		zw.Write([]byte("second\r\n"))
+		zw.Flush()
+	}()
+
+	output, err := tc.Drain(50 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("MCCP timeout: unexpected error of drain: %v", err)
+	}
+	if string(output) != "first\r\n" {
+		t.Errorf("MCCP timeout: wrong output of drain: %q", output)
+	}
+
+	close(next)
+	tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+	output = []byte{}
+	_, err = tc.ReadUntil(&output, '\n')
+	if err != nil {
+		t.Fatalf("MCCP timeout: stream is broken after timeout: %v", err)
+	}
+	if string(output) != "second\r\n" {
+		t.Errorf("MCCP timeout: wrong output after timeout: %q", output)
+	}
+}
@@ -0,0 +1,48 @@
+package telnet
+
+// MSSP variable and value markers
+const (
+	msspVar = 1
+	msspVal = 2
+)
+
+// MSSP returns variables of Mud Server Status Protocol received
+// from server. Variable may have several values.
+// EnableMSSP has to be set before Dial
+func (tc *TelnetClient) MSSP() map[string][]string {
+	return tc.mssp
+}
+
+// parseMSSP parses MSSP_VAR "name" MSSP_VAL "value" sequences
+func (tc *TelnetClient) parseMSSP(data []byte) {
+	var name string
+	var field []byte
+	var marker byte
+
+	if tc.mssp == nil {
+		tc.mssp = make(map[string][]string)
+	}
+
+	flush := func() {
+		switch marker {
+		case msspVar:
+			name = string(field)
+		case msspVal:
+			if name != "" {
+				tc.mssp[name] = append(tc.mssp[name], string(field))
+			}
+		}
+	}
+
+	for _, b := range data {
+		if b == msspVar || b == msspVal {
+			flush()
+			marker, field = b, field[:0]
+			continue
+		}
+		field = append(field, b)
+	}
+	flush()
+
+	tc.log("Received MSSP with %d variables", len(tc.mssp))
+}
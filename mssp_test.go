@@ -0,0 +1,41 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func Test_TelnetClient_MSSP(t *testing.T) {
+	data := []byte{0xff, 0xfb, 0x46, 0xff, 0xfa, 0x46}
+	data = append(data, "\x01NAME\x02Tiny MUD\x01PLAYERS\x0252"...)
+	data = append(data, "\x01PORT\x024000\x025000"...)
+	data = append(data, 0xff, 0xf0, '>')
+
+	sent := &bytes.Buffer{}
+	tc := &TelnetClient{
+		EnableMSSP: true,
+		reader:     bufio.NewReader(bytes.NewReader(data)),
+		writer:     bufio.NewWriter(sent),
+	}
+	tc.setupOptions()
+
+	b, err := tc.ReadByte()
+	if err != nil || b != '>' {
+		t.Fatalf("MSSP: unexpected result of ReadByte: %v, %v", b, err)
+	}
+
+	if want := []byte{0xff, 0xfd, 0x46}; bytes.Compare(sent.Bytes(), want) != 0 {
+		t.Errorf("MSSP: wrong negotiation: %v", sent.Bytes())
+	}
+
+	want := map[string][]string{
+		"NAME":    {"Tiny MUD"},
+		"PLAYERS": {"52"},
+		"PORT":    {"4000", "5000"},
+	}
+	if !reflect.DeepEqual(tc.MSSP(), want) {
+		t.Errorf("MSSP: wrong variables:\n\t\tfact = %v\n\t\twant = %v", tc.MSSP(), want)
+	}
+}
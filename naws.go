@@ -0,0 +1,28 @@
+package telnet
+
+import "encoding/binary"
+
+// SetWindowSize sets terminal size reported to server via NAWS.
+// If it is called before Dial, NAWS requested by server is accepted.
+// Later changes are sent to server immediately
+func (tc *TelnetClient) SetWindowSize(width, height int) error {
+	tc.windowSize = [2]uint16{uint16(width), uint16(height)}
+
+	if !tc.LocalEnabled(OptNAWS) {
+		return nil
+	}
+	defer tc.use()()
+
+	return tc.sendWindowSize()
+}
+
+// sendWindowSize sends IAC SB NAWS width height IAC SE
+func (tc *TelnetClient) sendWindowSize() error {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint16(data, tc.windowSize[0])
+	binary.BigEndian.PutUint16(data[2:], tc.windowSize[1])
+
+	tc.log("Send window size %dx%d", tc.windowSize[0], tc.windowSize[1])
+
+	return tc.sendSubnegotiation(OptNAWS, data)
+}
@@ -0,0 +1,40 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func Test_TelnetClient_SetWindowSize(t *testing.T) {
+	sent := &bytes.Buffer{}
+	tc := &TelnetClient{writer: bufio.NewWriter(sent)}
+	tc.reader = bufio.NewReader(bytes.NewReader([]byte{0xff, 0xfd, 0x1f, '$'}))
+
+	tc.SetWindowSize(80, 24)
+	if sent.Len() != 0 {
+		t.Errorf("SetWindowSize: data sent before negotiation: % x", sent.Bytes())
+	}
+	tc.setupOptions()
+
+	b, err := tc.ReadByte()
+	if err != nil || b != '$' {
+		t.Fatalf("SetWindowSize: unexpected result of ReadByte: %v, %v", b, err)
+	}
+	want := []byte{
+		0xff, 0xfb, 0x1f,
+		0xff, 0xfa, 0x1f, 0x00, 0x50, 0x00, 0x18, 0xff, 0xf0,
+	}
+	if bytes.Compare(sent.Bytes(), want) != 0 {
+		t.Errorf("SetWindowSize: wrong negotiation:\n\t\tfact = % x\n\t\twant = % x",
+			sent.Bytes(), want)
+	}
+
+	sent.Reset()
+	tc.SetWindowSize(132, 255)
+	want = []byte{0xff, 0xfa, 0x1f, 0x00, 0x84, 0x00, 0xff, 0xff, 0xff, 0xf0}
+	if bytes.Compare(sent.Bytes(), want) != 0 {
+		t.Errorf("SetWindowSize: wrong resize:\n\t\tfact = % x\n\t\twant = % x",
+			sent.Bytes(), want)
+	}
+}
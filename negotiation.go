@@ -0,0 +1,208 @@
+package telnet
+
+// optionState keeps negotiation state of a single option
+type optionState struct {
+	enabled bool
+	// requested means, that client has sent request of option
+	// and answer of server isn't received yet
+	requested bool
+	// onEnable is called, when option becomes enabled
+	onEnable func() error
+}
+
+// acceptRemote allows server to perform option,
+// i.e. client answers DO to WILL
+func (tc *TelnetClient) acceptRemote(opt Option, onEnable func() error) {
+	if tc.remoteOptions == nil {
+		tc.remoteOptions = make(map[Option]*optionState)
+	}
+
+	tc.remoteOptions[opt] = &optionState{onEnable: onEnable}
+}
+
+// acceptLocal allows client to perform option,
+// i.e. client answers WILL to DO
+func (tc *TelnetClient) acceptLocal(opt Option, onEnable func() error) {
+	if tc.localOptions == nil {
+		tc.localOptions = make(map[Option]*optionState)
+	}
+
+	tc.localOptions[opt] = &optionState{onEnable: onEnable}
+}
+
+// requestLocal offers option to server, i.e. client sends WILL.
+// Option is considered as enabled, until server refuses it
+func (tc *TelnetClient) requestLocal(opt Option, onEnable func() error) (err error) {
+	tc.acceptLocal(opt, onEnable)
+	tc.localOptions[opt].enabled = true
+	tc.localOptions[opt].requested = true
+
+	tc.log("Negotiate %s %s", CmdWILL, opt)
+	_, err = tc.write(negotiation(WILL, opt))
+	if err != nil || onEnable == nil {
+		return
+	}
+
+	return onEnable()
+}
+
+// requestRemote asks server to perform option, i.e. client sends DO.
+// Option is enabled, when server agrees
+func (tc *TelnetClient) requestRemote(opt Option, onEnable func() error) (err error) {
+	tc.acceptRemote(opt, onEnable)
+	tc.remoteOptions[opt].requested = true
+
+	tc.log("Negotiate %s %s", CmdDO, opt)
+	_, err = tc.write(negotiation(DO, opt))
+
+	return
+}
+
+// disableLocal stops performing option, i.e. client sends WONT
+func (tc *TelnetClient) disableLocal(opt Option) (err error) {
+	state, ok := tc.localOptions[opt]
+	if !ok || !state.enabled {
+		return
+	}
+	state.enabled = false
+	state.requested = true
+
+	tc.log("Negotiate %s %s", CmdWONT, opt)
+	_, err = tc.write(negotiation(WONT, opt))
+
+	return
+}
+
+// disableRemote asks server to stop performing option, i.e. client sends DONT
+func (tc *TelnetClient) disableRemote(opt Option) (err error) {
+	state, ok := tc.remoteOptions[opt]
+	if !ok || !state.enabled {
+		return
+	}
+	state.enabled = false
+	state.requested = true
+
+	tc.log("Negotiate %s %s", CmdDONT, opt)
+	_, err = tc.write(negotiation(DONT, opt))
+
+	return
+}
+
+// handleSubnegotiationOf registers built-in handler of option payloads
+func (tc *TelnetClient) handleSubnegotiationOf(opt Option, handler func(data []byte)) {
+	if tc.sbHandlers == nil {
+		tc.sbHandlers = make(map[Option]func(data []byte))
+	}
+
+	tc.sbHandlers[opt] = handler
+}
+
+// RemoteEnabled reports whether server performs option
+func (tc *TelnetClient) RemoteEnabled(opt Option) bool {
+	state, ok := tc.remoteOptions[opt]
+	return ok && state.enabled
+}
+
+// LocalEnabled reports whether client performs option
+func (tc *TelnetClient) LocalEnabled(opt Option) bool {
+	state, ok := tc.localOptions[opt]
+	return ok && state.enabled
+}
+
+// handleNegotiation answers WILL, WONT, DO, DONT commands of accepted options.
+// Requests of other options are ignored.
+// Answers are sent only when state changes, so negotiation doesn't loop
+func (tc *TelnetClient) handleNegotiation(cmd Command, opt Option) (err error) {
+	var state *optionState
+	var ok bool
+	var enable bool
+	var answer Command
+
+	switch cmd {
+	case WILL, WONT:
+		state, ok = tc.remoteOptions[opt]
+		enable = cmd == WILL
+		answer = DONT
+		if enable {
+			answer = DO
+		}
+	case DO, DONT:
+		state, ok = tc.localOptions[opt]
+		enable = cmd == DO
+		answer = WONT
+		if enable {
+			answer = WILL
+		}
+	default:
+		return
+	}
+
+	if !ok {
+		if enable {
+			tc.stats.RefusedOptions++
+		}
+		return
+	}
+	// Answer to request of client isn't acknowledged again
+	if state.requested {
+		state.requested = false
+		if state.enabled == enable {
+			return
+		}
+		state.enabled = enable
+		if !enable || state.onEnable == nil {
+			return
+		}
+		return state.onEnable()
+	}
+	if state.enabled == enable {
+		return
+	}
+	state.enabled = enable
+
+	tc.log("Negotiate %s %s", answer, opt)
+	_, err = tc.write(negotiation(answer, opt))
+	if err != nil || !enable || state.onEnable == nil {
+		return
+	}
+
+	return state.onEnable()
+}
+
+// setupOptions accepts options enabled in client parameters
+func (tc *TelnetClient) setupOptions() {
+	if tc.EnableMSSP {
+		tc.acceptRemote(OptMSSP, nil)
+		tc.handleSubnegotiationOf(OptMSSP, tc.parseMSSP)
+	}
+	if tc.EnableMCCP {
+		tc.acceptRemote(OptCompress2, nil)
+		tc.handleSubnegotiationOf(OptCompress2, tc.startCompression)
+	}
+	if tc.EORPrompts {
+		tc.acceptRemote(OptEndOfRecord, nil)
+	}
+	if tc.windowSize != [2]uint16{} {
+		tc.acceptLocal(OptNAWS, tc.sendWindowSize)
+	}
+	if tc.TerminalSpeed != "" {
+		tc.acceptLocal(OptTerminalSpeed, nil)
+		tc.handleSubnegotiationOf(OptTerminalSpeed, tc.sendTerminalSpeed)
+	}
+	if tc.Location != "" {
+		tc.acceptLocal(OptSendLocation, tc.sendLocation)
+	}
+	if tc.XDisplay != "" {
+		tc.acceptLocal(OptXDisplayLocation, nil)
+		tc.handleSubnegotiationOf(OptXDisplayLocation, tc.sendXDisplay)
+	}
+	if tc.EnableBinary {
+		tc.acceptRemote(OptBinary, nil)
+		tc.acceptLocal(OptBinary, nil)
+	}
+	if tc.EnableFlowControl {
+		tc.flowControl = true
+		tc.acceptLocal(OptToggleFlowControl, nil)
+		tc.handleSubnegotiationOf(OptToggleFlowControl, tc.toggleFlowControl)
+	}
+}
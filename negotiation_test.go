@@ -0,0 +1,39 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func Test_TelnetClient_requestLocal(t *testing.T) {
+	tests := []struct {
+		name        string
+		answer      []byte
+		wantEnabled bool
+	}{
+		{"accepted", []byte{0xff, 0xfd, 0x00}, true},
+		{"refused", []byte{0xff, 0xfe, 0x00}, false},
+	}
+
+	for _, tt := range tests {
+		sent := &bytes.Buffer{}
+		tc := &TelnetClient{writer: bufio.NewWriter(sent)}
+		tc.reader = bufio.NewReader(bytes.NewReader(append(tt.answer, '$')))
+
+		if err := tc.requestLocal(OptBinary, nil); err != nil {
+			t.Fatalf("[%s] requestLocal: unexpected error: %v", tt.name, err)
+		}
+		if b, err := tc.ReadByte(); err != nil || b != '$' {
+			t.Fatalf("[%s] requestLocal: unexpected result of ReadByte: %v, %v", tt.name, b, err)
+		}
+
+		// Answer of server isn't acknowledged
+		if want := []byte{0xff, 0xfb, 0x00}; !bytes.Equal(sent.Bytes(), want) {
+			t.Errorf("[%s] requestLocal: wrong sent data: % x", tt.name, sent.Bytes())
+		}
+		if enabled := tc.LocalEnabled(OptBinary); enabled != tt.wantEnabled {
+			t.Errorf("[%s] requestLocal: option is enabled %v, want %v", tt.name, enabled, tt.wantEnabled)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package telnet
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// ExecuteNoWait sends command and doesn't wait for its output.
+// Output is left in the stream and discarded before the next command
+func (tc *TelnetClient) ExecuteNoWait(name string, args ...string) (err error) {
+	defer tc.use()()
+
+	request := commandRequest(name, args)
+	err = tc.checkPolicy(string(request[:len(request)-2]))
+	if err != nil {
+		return
+	}
+	if tc.DryRun {
+		tc.recordDryRun(string(request[:len(request)-2]))
+		return
+	}
+
+	err = tc.discardBuffered()
+	if err != nil {
+		return
+	}
+
+	tc.limitCommand()
+	tc.log("Send command without waiting: %s", request[:len(request)-2])
+	_, err = tc.write(tc.outgoingCR(request))
+
+	return
+}
+
+// ExecuteExpectSilence sends command, which isn't expected to return
+// a prompt (e.g. "reload"), and reads output until server is quiet
+// for timeout. Connection dropped by server isn't an error
+func (tc *TelnetClient) ExecuteExpectSilence(
+	timeout time.Duration,
+	name string,
+	args ...string,
+) (output []byte, err error) {
+	defer tc.use()()
+
+	err = tc.ExecuteNoWait(name, args...)
+	if err != nil {
+		return
+	}
+
+	output, err = tc.drain(timeout, time.Time{})
+	if isDropped(err) {
+		tc.log("Connection is dropped by server after %d bytes of output", len(output))
+		err = nil
+	}
+
+	return
+}
+
+// isDropped reports whether error is caused by connection closed
+// or reset by remote side
+func isDropped(err error) bool {
+	if err == nil || err == ErrClosed {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.ErrClosedPipe) {
+		return true
+	}
+
+	var ne net.Error
+	return errors.As(err, &ne) && !ne.Timeout()
+}
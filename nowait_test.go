@@ -0,0 +1,56 @@
+package telnet
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_ExecuteExpectSilence(t *testing.T) {
+	tests := []struct {
+		name       string
+		disconnect bool
+	}{
+		{name: "ExecuteExpectSilence: device is silent"},
+		{name: "ExecuteExpectSilence: device drops connection", disconnect: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer server.Close()
+
+			tc := &TelnetClient{ReadTimeout: time.Second}
+			tc.setConn(client)
+			defer tc.Close()
+
+			disconnect := tt.disconnect
+			received := make(chan []byte, 1)
+			go func() {
+				command := make([]byte, 64)
+				n, _ := server.Read(command)
+				received <- command[:n]
+
+				server.Write([]byte("Proceed with reload\r\n"))
+				if disconnect {
+					server.Close()
+				}
+			}()
+
+			start := time.Now()
+			output, err := tc.ExecuteExpectSilence(50*time.Millisecond, "reload")
+			if err != nil {
+				t.Fatalf("[%s] unexpected error: %v", tt.name, err)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("[%s] took %v", tt.name, elapsed)
+			}
+			if want := []byte("Proceed with reload\r\n"); bytes.Compare(output, want) != 0 {
+				t.Errorf("[%s] wrong output: %q", tt.name, output)
+			}
+			if command := <-received; string(command) != "reload \r\n" {
+				t.Errorf("[%s] wrong command: %q", tt.name, command)
+			}
+		})
+	}
+}
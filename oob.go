@@ -0,0 +1,207 @@
+package telnet
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MSDP markers
+const (
+	msdpVar        = 1
+	msdpVal        = 2
+	msdpTableOpen  = 3
+	msdpTableClose = 4
+	msdpArrayOpen  = 5
+	msdpArrayClose = 6
+)
+
+// OOBMessage is a decoded out-of-band message of GMCP or MSDP
+type OOBMessage struct {
+	Option Option
+	// Name is GMCP package (e.g. "Char.Vitals") or MSDP variable
+	Name string
+	// Data is JSON payload of GMCP or MSDP value converted to JSON
+	Data json.RawMessage
+}
+
+// RegisterOOB accepts out-of-band protocol (OptGMCP or OptMSDP)
+// offered by server and returns channel of decoded messages.
+// Reading isn't blocked by a slow consumer: if channel is full,
+// messages are dropped
+func (tc *TelnetClient) RegisterOOB(opt Option, buffer int) <-chan OOBMessage {
+	messages := make(chan OOBMessage, buffer)
+
+	decode := decodeGMCP
+	if opt == OptMSDP {
+		decode = decodeMSDP
+	}
+
+	tc.acceptRemote(opt, nil)
+	tc.handleSubnegotiationOf(opt, func(data []byte) {
+		for _, msg := range decode(data) {
+			msg.Option = opt
+			select {
+			case messages <- msg:
+			default:
+				tc.log("Drop %s message %s, channel is full", opt, msg.Name)
+			}
+		}
+	})
+
+	return messages
+}
+
+// SendGMCP sends GMCP message with value encoded to JSON.
+// Nil value sends package name only
+func (tc *TelnetClient) SendGMCP(name string, value interface{}) (err error) {
+	payload := []byte(name)
+
+	if value != nil {
+		var data []byte
+
+		data, err = json.Marshal(value)
+		if err != nil {
+			return
+		}
+		payload = append(append(payload, ' '), data...)
+	}
+
+	return tc.SendSubnegotiation(OptGMCP, payload)
+}
+
+// SendMSDP sends MSDP variable with values, e.g. "REPORT", "HEALTH"
+func (tc *TelnetClient) SendMSDP(name string, values ...string) error {
+	payload := append([]byte{msdpVar}, name...)
+	for _, v := range values {
+		payload = append(append(payload, msdpVal), v...)
+	}
+
+	return tc.SendSubnegotiation(OptMSDP, payload)
+}
+
+// decodeGMCP splits "Package.Name <json>" payload
+func decodeGMCP(data []byte) []OOBMessage {
+	msg := OOBMessage{Name: string(data)}
+
+	if n := bytes.IndexByte(data, ' '); n != -1 {
+		msg.Name = string(data[:n])
+		msg.Data = json.RawMessage(bytes.TrimSpace(data[n+1:]))
+	}
+
+	return []OOBMessage{msg}
+}
+
+// decodeMSDP converts MSDP variables to messages with JSON values
+func decodeMSDP(data []byte) (messages []OOBMessage) {
+	d := &msdpDecoder{data: data}
+
+	for d.pos < len(d.data) {
+		if d.data[d.pos] != msdpVar {
+			d.pos++
+			continue
+		}
+		d.pos++
+
+		name := d.readString()
+		value := d.readValue()
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		messages = append(messages, OOBMessage{Name: name, Data: raw})
+	}
+
+	return
+}
+
+type msdpDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msdpDecoder) readString() string {
+	start := d.pos
+	for d.pos < len(d.data) && d.data[d.pos] > msdpArrayClose {
+		d.pos++
+	}
+
+	return string(d.data[start:d.pos])
+}
+
+// readValue reads MSDP_VAL followed by string, table or array.
+// Several values of the same variable are converted to array
+func (d *msdpDecoder) readValue() interface{} {
+	var values []interface{}
+
+	for d.pos < len(d.data) && d.data[d.pos] == msdpVal {
+		d.pos++
+		values = append(values, d.readSingle())
+	}
+
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	}
+
+	return values
+}
+
+// readSingle reads string, table or array following MSDP_VAL
+func (d *msdpDecoder) readSingle() interface{} {
+	if d.pos >= len(d.data) {
+		return ""
+	}
+
+	switch d.data[d.pos] {
+	case msdpTableOpen:
+		d.pos++
+		return d.readTable()
+	case msdpArrayOpen:
+		d.pos++
+		return d.readArray()
+	}
+
+	return d.readString()
+}
+
+func (d *msdpDecoder) readTable() map[string]interface{} {
+	table := make(map[string]interface{})
+
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case msdpTableClose:
+			d.pos++
+			return table
+		case msdpVar:
+			d.pos++
+			name := d.readString()
+			table[name] = d.readValue()
+		default:
+			d.pos++
+		}
+	}
+
+	return table
+}
+
+func (d *msdpDecoder) readArray() []interface{} {
+	array := []interface{}{}
+
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case msdpArrayClose:
+			d.pos++
+			return array
+		case msdpVal:
+			d.pos++
+			array = append(array, d.readSingle())
+		default:
+			d.pos++
+		}
+	}
+
+	return array
+}
@@ -0,0 +1,60 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func Test_decodeMSDP(t *testing.T) {
+	data := []byte("\x01HEALTH\x02100\x01ROOM\x02\x03\x01VNUM\x026008\x01EXITS\x02\x05\x02n\x02e\x06\x04")
+
+	want := []OOBMessage{
+		{Name: "HEALTH", Data: []byte(`"100"`)},
+		{Name: "ROOM", Data: []byte(`{"EXITS":["n","e"],"VNUM":"6008"}`)},
+	}
+
+	fact := decodeMSDP(data)
+	if len(fact) != len(want) {
+		t.Fatalf("decodeMSDP: wrong number of messages: %v", fact)
+	}
+	for i := range want {
+		if fact[i].Name != want[i].Name || bytes.Compare(fact[i].Data, want[i].Data) != 0 {
+			t.Errorf(
+				"decodeMSDP: wrong message:\n\t\tfact = %s %s\n\t\twant = %s %s",
+				fact[i].Name, fact[i].Data, want[i].Name, want[i].Data)
+		}
+	}
+}
+
+func Test_TelnetClient_RegisterOOB(t *testing.T) {
+	data := []byte{0xff, 0xfb, 0xc9, 0xff, 0xfa, 0xc9}
+	data = append(data, `Char.Vitals {"hp": 100, "mp": 50}`...)
+	data = append(data, 0xff, 0xf0, '>')
+
+	sent := &bytes.Buffer{}
+	tc := &TelnetClient{
+		reader: bufio.NewReader(bytes.NewReader(data)),
+		writer: bufio.NewWriter(sent),
+	}
+	messages := tc.RegisterOOB(OptGMCP, 1)
+
+	b, err := tc.ReadByte()
+	if err != nil || b != '>' {
+		t.Fatalf("RegisterOOB: unexpected result of ReadByte: %v, %v", b, err)
+	}
+
+	msg := <-messages
+	if msg.Option != OptGMCP || msg.Name != "Char.Vitals" ||
+		string(msg.Data) != `{"hp": 100, "mp": 50}` {
+		t.Errorf("RegisterOOB: wrong message: %v %s %s", msg.Option, msg.Name, msg.Data)
+	}
+
+	sent.Reset()
+	tc.SendGMCP("Core.Supports.Set", []string{"Char 1"})
+	want := append([]byte{0xff, 0xfa, 0xc9}, `Core.Supports.Set ["Char 1"]`...)
+	want = append(want, 0xff, 0xf0)
+	if bytes.Compare(sent.Bytes(), want) != 0 {
+		t.Errorf("SendGMCP: wrong data: %q", sent.Bytes())
+	}
+}
@@ -0,0 +1,143 @@
+package telnet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrEchoMismatch is returned by SendPaced when the echoed line
+// differs from the sent one
+var ErrEchoMismatch = errors.New("telnet: echoed line does not match sent data")
+
+// EchoMismatchError describes the line which echo verification failed on
+type EchoMismatchError struct {
+	// Line is 1-based number of failed line
+	Line   int
+	Sent   []byte
+	Echoed []byte
+}
+
+func (e *EchoMismatchError) Error() string {
+	return fmt.Sprintf(
+		"telnet: echo mismatch on line %d: sent %q, echoed %q",
+		e.Line, e.Sent, e.Echoed)
+}
+
+// Unwrap allows to match error with ErrEchoMismatch via errors.Is
+func (e *EchoMismatchError) Unwrap() error {
+	return ErrEchoMismatch
+}
+
+// PacingOptions describes how SendPaced splits and delays outgoing data
+type PacingOptions struct {
+	// ChunkSize is the maximum number of bytes written at once.
+	// Zero means whole line is written at once
+	ChunkSize int
+	// ChunkDelay is a pause between chunks of the same line
+	ChunkDelay time.Duration
+	// LineDelay is a pause after each line
+	LineDelay time.Duration
+	// VerifyEcho enables reading back the echo of every line
+	// and comparing it with the sent one before the next line is sent.
+	// On mismatch SendPaced stops and returns *EchoMismatchError
+	VerifyEcho bool
+}
+
+// SendPaced sends large data (e.g. device config) line by line.
+// Every line is terminated by CR LF and written by chunks
+// with configured delays, so device input buffers are not overrun.
+// Trailing data without line feed is sent as is
+func (tc *TelnetClient) SendPaced(data []byte, opts PacingOptions) (err error) {
+	defer tc.use()()
+
+	for lineNum := 1; len(data) > 0; lineNum++ {
+		var line []byte
+		complete := false
+
+		n := bytes.IndexByte(data, '\n')
+		if n == -1 {
+			line, data = data, nil
+		} else {
+			line, data = bytes.TrimRight(data[:n], "\r"), data[n+1:]
+			complete = true
+		}
+
+		err = tc.sendPacedLine(line, complete, opts)
+		if err != nil {
+			return
+		}
+
+		// Nothing is echoed in DryRun mode
+		if complete && opts.VerifyEcho && !tc.DryRun {
+			err = tc.verifyEcho(lineNum, line)
+			if err != nil {
+				return
+			}
+		}
+
+		if opts.LineDelay > 0 {
+			time.Sleep(opts.LineDelay)
+		}
+	}
+
+	return
+}
+
+func (tc *TelnetClient) sendPacedLine(
+	line []byte,
+	complete bool,
+	opts PacingOptions,
+) (err error) {
+	payload := make([]byte, 0, len(line)+2)
+	payload = append(payload, line...)
+	if complete {
+		payload = append(payload, '\r', '\n')
+	}
+
+	payload = tc.outgoingCR(payload)
+
+	size := opts.ChunkSize
+	if size <= 0 {
+		size = len(payload)
+	}
+
+	for i := 0; i < len(payload); i += size {
+		if i > 0 && opts.ChunkDelay > 0 {
+			time.Sleep(opts.ChunkDelay)
+		}
+
+		end := i + size
+		if end > len(payload) {
+			end = len(payload)
+		}
+
+		_, err = tc.write(payload[i:end])
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// verifyEcho reads a single echoed line and checks that it ends with
+// the sent line. Echo may be prefixed by the prompt of previous line.
+// Every echo is waited for ReadTimeout
+func (tc *TelnetClient) verifyEcho(lineNum int, line []byte) (err error) {
+	echo := make([]byte, 0, len(line)+64)
+
+	tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+	_, err = tc.readUntil(&echo, '\n')
+	if err != nil {
+		return
+	}
+
+	echo = bytes.TrimRight(echo, "\r\n")
+	if !bytes.HasSuffix(echo, line) {
+		err = &EchoMismatchError{Line: lineNum, Sent: line, Echoed: echo}
+	}
+
+	return
+}
@@ -0,0 +1,132 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// echoServer records lines written by client and writes them back
+// through the transform function. Echo is written asynchronously,
+// so server never blocks if client doesn't read it
+func echoServer(
+	r io.Reader,
+	w io.Writer,
+	sent *bytes.Buffer,
+	transform func([]byte) []byte,
+) {
+	echoCh := make(chan []byte, 64)
+	defer close(echoCh)
+
+	go func() {
+		for echo := range echoCh {
+			if len(echo) > 0 {
+				w.Write(echo)
+			}
+		}
+	}()
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		sent.Write(line)
+		if err != nil {
+			return
+		}
+
+		echoCh <- transform(bytes.TrimRight(line, "\r\n"))
+	}
+}
+
+func Test_TelnetClient_SendPaced(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		opts      PacingOptions
+		transform func([]byte) []byte
+		want      []byte
+		wantErr   error
+		wantLine  int
+		timeout   bool
+	}{
+		{
+			name:      "SendPaced: chunked lines",
+			data:      []byte("interface eth0\nip address 10.0.0.1/24\r\nexit\n"),
+			opts:      PacingOptions{ChunkSize: 4, ChunkDelay: time.Millisecond},
+			transform: func(b []byte) []byte { return append(b, '\r', '\n') },
+			want:      []byte("interface eth0\r\nip address 10.0.0.1/24\r\nexit\r\n"),
+		},
+		{
+			name: "SendPaced: verified echo with prompt",
+			data: []byte("hostname R1\nexit\n"),
+			opts: PacingOptions{VerifyEcho: true},
+			transform: func(b []byte) []byte {
+				return append([]byte("R(config)# "), append(b, '\r', '\n')...)
+			},
+			want: []byte("hostname R1\r\nexit\r\n"),
+		},
+		{
+			name: "SendPaced: dropped characters",
+			data: []byte("interface eth0\nhostname R1\nexit\n"),
+			opts: PacingOptions{VerifyEcho: true},
+			transform: func(b []byte) []byte {
+				return append(bytes.Replace(b, []byte("R1"), []byte("R"), 1), '\r', '\n')
+			},
+			want:     []byte("interface eth0\r\nhostname R1\r\n"),
+			wantErr:  ErrEchoMismatch,
+			wantLine: 2,
+		},
+		{
+			name:      "SendPaced: no echo",
+			data:      []byte("hostname R1\nexit\n"),
+			opts:      PacingOptions{VerifyEcho: true},
+			transform: func(b []byte) []byte { return nil },
+			want:      []byte("hostname R1\r\n"),
+			timeout:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer server.Close()
+			sent := &bytes.Buffer{}
+
+			tc := &TelnetClient{ReadTimeout: 100 * time.Millisecond}
+			tc.setConn(client)
+
+			doneCh := make(chan bool)
+			go func() {
+				echoServer(server, server, sent, tt.transform)
+				doneCh <- true
+			}()
+
+			err := tc.SendPaced(tt.data, tt.opts)
+			client.Close()
+			<-doneCh
+
+			if tt.timeout {
+				if !isTimeout(err) {
+					t.Errorf("[%s] timeout is expected: %v", tt.name, err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("[%s] unexpected error: %v", tt.name, err)
+			}
+			var mismatch *EchoMismatchError
+			if errors.As(err, &mismatch) && mismatch.Line != tt.wantLine {
+				t.Errorf(
+					"[%s] wrong failed line: fact = %d, want = %d",
+					tt.name, mismatch.Line, tt.wantLine)
+			}
+			if bytes.Compare(sent.Bytes(), tt.want) != 0 {
+				t.Errorf(
+					"[%s] wrong sent data:\n\t\tfact = %q\n\t\twant = %q",
+					tt.name, sent.Bytes(), tt.want)
+			}
+		})
+	}
+}
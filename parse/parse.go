@@ -0,0 +1,127 @@
+// Package parse converts common command outputs of network devices
+// into maps, so callers of Execute don't split strings by hand
+package parse
+
+import (
+	"strings"
+)
+
+// Table parses fixed-width table, e.g. output of "show ip interface brief".
+// The first non-empty line is a header. Columns start at header words,
+// or at dash groups of separator line (e.g. "---- -----------"),
+// if it follows the header, so names with spaces (e.g. "Mac Address")
+// are supported. Rows are returned as maps of column names to trimmed values
+func Table(output []byte) (rows []map[string]string) {
+	lines := splitLines(output)
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	header := lines[0]
+	lines = lines[1:]
+	starts := fieldStarts(header)
+	if len(lines) > 0 && isSeparator(lines[0]) {
+		starts = fieldStarts(lines[0])
+		lines = lines[1:]
+	}
+
+	names := splitColumns(header, starts)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" || isSeparator(line) {
+			continue
+		}
+
+		row := make(map[string]string, len(names))
+		for i, value := range splitColumns(line, starts) {
+			if names[i] != "" {
+				row[names[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return
+}
+
+// KV parses lines like "key: value" with separator sep,
+// e.g. details of "show version". Lines without separator are skipped.
+// Keys and values are trimmed
+func KV(output []byte, sep string) map[string]string {
+	values := make(map[string]string)
+
+	for _, line := range splitLines(output) {
+		n := strings.Index(line, sep)
+		if n == -1 {
+			continue
+		}
+
+		key := strings.TrimSpace(line[:n])
+		if key == "" {
+			continue
+		}
+		values[key] = strings.TrimSpace(line[n+len(sep):])
+	}
+
+	return values
+}
+
+// splitLines splits output to lines without line endings
+func splitLines(output []byte) []string {
+	lines := strings.Split(string(output), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r")
+	}
+
+	return lines
+}
+
+// fieldStarts returns positions of words in line
+func fieldStarts(line string) (starts []int) {
+	for i := 0; i < len(line); i++ {
+		if line[i] != ' ' && (i == 0 || line[i-1] == ' ') {
+			starts = append(starts, i)
+		}
+	}
+
+	return
+}
+
+// isSeparator reports whether line consists of dashes and spaces only
+func isSeparator(line string) bool {
+	line = strings.TrimSpace(line)
+	return line != "" && strings.Trim(line, "- ") == ""
+}
+
+// splitColumns cuts line at column starts. A value longer than its column
+// pushes the boundary to the next space, so it isn't split
+func splitColumns(line string, starts []int) []string {
+	values := make([]string, len(starts))
+
+	for i := range starts {
+		begin := boundary(line, starts[i])
+		end := len(line)
+		if i+1 < len(starts) {
+			end = boundary(line, starts[i+1])
+		}
+		if begin < end {
+			values[i] = strings.TrimSpace(line[begin:end])
+		}
+	}
+
+	return values
+}
+
+// boundary moves column start out of a word, which crosses it
+func boundary(line string, pos int) int {
+	if pos >= len(line) {
+		return len(line)
+	}
+	for pos > 0 && pos < len(line) && line[pos-1] != ' ' && line[pos] != ' ' {
+		pos++
+	}
+
+	return pos
+}
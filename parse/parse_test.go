@@ -0,0 +1,78 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Table(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []map[string]string
+	}{
+		{
+			name: "header words",
+			output: "\r\nInterface              IP-Address      OK? Method Status                Protocol\r\n" +
+				"GigabitEthernet0/0     10.0.0.1        YES NVRAM  up                    up      \r\n" +
+				"GigabitEthernet0/1     unassigned      YES unset  administratively down down    \r\n",
+			want: []map[string]string{
+				{
+					"Interface": "GigabitEthernet0/0", "IP-Address": "10.0.0.1", "OK?": "YES",
+					"Method": "NVRAM", "Status": "up", "Protocol": "up",
+				},
+				{
+					"Interface": "GigabitEthernet0/1", "IP-Address": "unassigned", "OK?": "YES",
+					"Method": "unset", "Status": "administratively down", "Protocol": "down",
+				},
+			},
+		},
+		{
+			name: "separator line",
+			output: "Vlan    Mac Address       Type        Ports\r\n" +
+				"----    -----------       --------    -----\r\n" +
+				"   1    0011.2233.4455    DYNAMIC     Gi0/1\r\n" +
+				"  10    0011.2233.4466    STATIC      CPU\r\n",
+			want: []map[string]string{
+				{"Vlan": "1", "Mac Address": "0011.2233.4455", "Type": "DYNAMIC", "Ports": "Gi0/1"},
+				{"Vlan": "10", "Mac Address": "0011.2233.4466", "Type": "STATIC", "Ports": "CPU"},
+			},
+		},
+		{
+			name: "value longer than column",
+			output: "Port      Name    Status\n" +
+				"Gi0/1     uplink-to-core connected\n" +
+				"Gi0/2             notconnect\n",
+			want: []map[string]string{
+				{"Port": "Gi0/1", "Name": "uplink-to-core", "Status": "connected"},
+				{"Port": "Gi0/2", "Name": "", "Status": "notconnect"},
+			},
+		},
+		{
+			name:   "empty output",
+			output: "\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if rows := Table([]byte(tt.output)); !reflect.DeepEqual(rows, tt.want) {
+			t.Errorf("[%s] Table: wrong rows:\n\t\tfact = %q\n\t\twant = %q", tt.name, rows, tt.want)
+		}
+	}
+}
+
+func Test_KV(t *testing.T) {
+	output := "Cisco IOS Software, C2960 Software\r\n" +
+		"System serial number            : FOC1234X0AB\r\n" +
+		"Model number                    : WS-C2960-24TT-L\r\n" +
+		"Uptime: 5 weeks, 2 days\r\n"
+
+	want := map[string]string{
+		"System serial number": "FOC1234X0AB",
+		"Model number":         "WS-C2960-24TT-L",
+		"Uptime":               "5 weeks, 2 days",
+	}
+	if values := KV([]byte(output), ":"); !reflect.DeepEqual(values, want) {
+		t.Errorf("KV: wrong values:\n\t\tfact = %q\n\t\twant = %q", values, want)
+	}
+}
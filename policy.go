@@ -0,0 +1,75 @@
+package telnet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPolicyDenied is returned, when command is denied by CommandPolicy
+var ErrPolicyDenied = errors.New("telnet: command denied by policy")
+
+// CommandPolicy decides whether command may be sent to device,
+// e.g. to enforce read-only operation. Reason explains denial
+type CommandPolicy func(command string) (allow bool, reason string)
+
+// chainOperators run another command in shells and CLIs of devices,
+// e.g. "show version; reload" or "show run | redirect tftp://..."
+var chainOperators = []string{";", "|", "&", "`", "$("}
+
+// ReadOnlyPolicy allows only commands starting with one of prefixes,
+// e.g. "show", "display", "ping". Prefix is matched as whole words,
+// so "show" doesn't allow "shower". Commands containing chain operators
+// (";", "|", "&", "`", "$(") are denied
+func ReadOnlyPolicy(prefixes ...string) CommandPolicy {
+	return func(command string) (bool, string) {
+		for _, operator := range chainOperators {
+			if strings.Contains(command, operator) {
+				return false, fmt.Sprintf("chained commands (%q) are not allowed", operator)
+			}
+		}
+
+		for _, prefix := range prefixes {
+			if command == prefix || strings.HasPrefix(command, prefix+" ") {
+				return true, ""
+			}
+		}
+
+		return false, "only read-only commands are allowed"
+	}
+}
+
+// checkPolicy returns ErrPolicyDenied, if CommandPolicy denies command.
+// Each line of command is checked separately, as device executes
+// every line as a command
+func (tc *TelnetClient) checkPolicy(command string) error {
+	if tc.CommandPolicy == nil {
+		return nil
+	}
+
+	lines := strings.FieldsFunc(command, func(r rune) bool {
+		return r == '\r' || r == '\n'
+	})
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	for _, line := range lines {
+		if err := tc.checkPolicyLine(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkPolicyLine checks single line of command
+func (tc *TelnetClient) checkPolicyLine(command string) error {
+	command = strings.TrimSpace(command)
+	allow, reason := tc.CommandPolicy(command)
+	if allow {
+		return nil
+	}
+	tc.log("Command %q is denied by policy: %s", command, reason)
+
+	return fmt.Errorf("%w: %q: %s", ErrPolicyDenied, command, reason)
+}
@@ -0,0 +1,79 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func Test_TelnetClient_CommandPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		denied  bool
+	}{
+		{name: "CommandPolicy: allowed", command: "show", args: []string{"version"}},
+		{name: "CommandPolicy: denied", command: "reload", denied: true},
+		{name: "CommandPolicy: denied with args", command: "configure", args: []string{"terminal"}, denied: true},
+		{name: "CommandPolicy: whole word", command: "shower", denied: true},
+		{name: "CommandPolicy: prefix only", command: "show"},
+		{name: "CommandPolicy: injected line", command: "show", args: []string{"version\r\nreload"}, denied: true},
+		{name: "CommandPolicy: injected LF", command: "show version\nreload", denied: true},
+		{name: "CommandPolicy: semicolon", command: "show version; reload", denied: true},
+		{name: "CommandPolicy: pipe", command: "show", args: []string{"run", "|", "redirect", "tftp://10.0.0.1/r1"}, denied: true},
+		{name: "CommandPolicy: ampersand", command: "show version && reboot", denied: true},
+		{name: "CommandPolicy: backtick", command: "show `reboot`", denied: true},
+		{name: "CommandPolicy: substitution", command: "ping $(reboot)", denied: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := &bytes.Buffer{}
+			tc := &TelnetClient{
+				CommandPolicy: ReadOnlyPolicy("show", "ping"),
+				writer:        bufio.NewWriter(sent),
+			}
+			tc.reader = bufio.NewReader(&bytes.Buffer{})
+
+			err := tc.ExecuteNoWait(tt.command, tt.args...)
+			if errors.Is(err, ErrPolicyDenied) != tt.denied {
+				t.Errorf("[%s] unexpected error: %v", tt.name, err)
+			}
+			if tt.denied && sent.Len() != 0 {
+				t.Errorf("[%s] denied command is sent: %q", tt.name, sent.String())
+			}
+
+			if tt.denied {
+				_, err = tc.Execute(tt.command, tt.args...)
+				if !errors.Is(err, ErrPolicyDenied) || sent.Len() != 0 {
+					t.Errorf("[%s] Execute: unexpected result: %v, %q", tt.name, err, sent.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_TelnetClient_CommandPolicyBlock(t *testing.T) {
+	tests := []struct {
+		lines  []string
+		denied bool
+	}{
+		{lines: []string{"show clock", "show version"}},
+		{lines: []string{"show clock", "reload"}, denied: true},
+		{lines: []string{"show clock\rreload"}, denied: true},
+	}
+	for _, tt := range tests {
+		sent := &bytes.Buffer{}
+		tc := &TelnetClient{
+			CommandPolicy: ReadOnlyPolicy("show"),
+			DryRun:        true,
+			writer:        bufio.NewWriter(sent),
+		}
+
+		_, err := tc.ExecuteBlock(tt.lines...)
+		if errors.Is(err, ErrPolicyDenied) != tt.denied {
+			t.Errorf("ExecuteBlock: unexpected error of %q: %v", tt.lines, err)
+		}
+	}
+}
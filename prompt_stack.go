@@ -0,0 +1,81 @@
+package telnet
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PromptRule tells, which prompt appears after command,
+// e.g. "configure terminal" enters configuration mode.
+// The prompt is pushed before command is sent, if the previous
+// prompt is received instead (command failed), it is popped back
+type PromptRule struct {
+	Command *regexp.Regexp
+	Push    *regexp.Regexp
+}
+
+// CiscoPromptRules returns prompt rules of Cisco IOS device with hostname
+func CiscoPromptRules(hostname string) []PromptRule {
+	return []PromptRule{
+		{
+			Command: regexp.MustCompile(`^conf(igure)?(\s+t(erminal)?)?\s*$`),
+			Push:    regexp.MustCompile(regexp.QuoteMeta(hostname) + `\(config[^)]*\)#`),
+		},
+	}
+}
+
+// JunosPromptRules returns prompt rules of Juniper device,
+// which prompt is "user@host>"
+func JunosPromptRules(user, host string) []PromptRule {
+	return []PromptRule{
+		{
+			Command: regexp.MustCompile(`^conf(igure)?(\s+(private|exclusive))?\s*$`),
+			Push:    regexp.MustCompile(regexp.QuoteMeta(user+"@"+host) + `#`),
+		},
+	}
+}
+
+// PushPrompt makes re the current prompt, e.g. after entering
+// configuration mode. Previous prompt is restored by PopPrompt
+func (tc *TelnetClient) PushPrompt(re *regexp.Regexp) {
+	tc.promptStack = append(tc.promptStack, tc.BannerRe)
+	tc.BannerRe = re
+	tc.log("Push prompt %q", re)
+}
+
+// PopPrompt restores previous prompt. It returns false,
+// if there is no prompt pushed
+func (tc *TelnetClient) PopPrompt() bool {
+	n := len(tc.promptStack)
+	if n == 0 {
+		return false
+	}
+
+	tc.BannerRe, tc.promptStack = tc.promptStack[n-1], tc.promptStack[:n-1]
+	tc.log("Pop prompt, current is %q", tc.BannerRe)
+
+	return true
+}
+
+// promptLevels returns the current prompt followed by prompts under it
+func (tc *TelnetClient) promptLevels() []*regexp.Regexp {
+	levels := make([]*regexp.Regexp, 0, len(tc.promptStack)+1)
+	levels = append(levels, tc.BannerRe)
+	for i := len(tc.promptStack) - 1; i >= 0; i-- {
+		levels = append(levels, tc.promptStack[i])
+	}
+
+	return levels
+}
+
+// trackPrompt pushes prompt of the first PromptRules rule matching command,
+// so output of the command is read until the new prompt
+func (tc *TelnetClient) trackPrompt(command string) {
+	command = strings.TrimSpace(command)
+	for _, rule := range tc.PromptRules {
+		if rule.Command.MatchString(command) {
+			tc.PushPrompt(rule.Push)
+			return
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package telnet
+
+import (
+	"net"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_PushPrompt(t *testing.T) {
+	base := regexp.MustCompile("R1#")
+	config := regexp.MustCompile(`R1\(config\)#`)
+	tc := &TelnetClient{BannerRe: base}
+
+	tc.PushPrompt(config)
+	if tc.BannerRe != config {
+		t.Errorf("PushPrompt: current prompt isn't changed: %q", tc.BannerRe)
+	}
+	if !tc.PopPrompt() || tc.BannerRe != base {
+		t.Errorf("PopPrompt: previous prompt isn't restored: %q", tc.BannerRe)
+	}
+	if tc.PopPrompt() {
+		t.Errorf("PopPrompt: empty stack is popped")
+	}
+}
+
+func Test_TelnetClient_PromptRules(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout: time.Second,
+		BannerRe:    regexp.MustCompile("R1#"),
+		PromptRules: CiscoPromptRules("R1"),
+	}
+	tc.setConn(client)
+
+	steps := []struct {
+		command   string
+		response  string
+		wantDepth int
+	}{
+		{"configure terminal", "Enter configuration commands, one per line.\r\nR1(config)#", 1},
+		{"interface Gi0/1", "R1(config-if)#", 1},
+		{"exit", "R1(config)#", 1},
+		{"exit", "R1#", 0},
+		{"conf t", "% Configuration mode is locked by another session\r\nR1#", 0},
+	}
+
+	// server
+	go func() {
+		buf := make([]byte, 64)
+		for _, step := range steps {
+			if _, err := server.Read(buf); err != nil {
+				return
+			}
+			server.Write([]byte(step.response))
+		}
+	}()
+
+	for _, step := range steps {
+		if _, err := tc.Execute(step.command); err != nil {
+			t.Fatalf("PromptRules: unexpected error of %q: %v", step.command, err)
+		}
+		if depth := len(tc.promptStack); depth != step.wantDepth {
+			t.Errorf("PromptRules: wrong depth of stack after %q: %d", step.command, depth)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package telnet
+
+import "regexp"
+
+// PromptVersion selects default LoginRe, PasswordRe and BannerRe
+type PromptVersion int
+
+const (
+	// PromptsV1 are original defaults matching ASCII names on a single line
+	PromptsV1 PromptVersion = iota
+	// PromptsV2 match UTF-8 host names and paths, prompts wrapped
+	// by terminal, "Username:" and case-insensitive password prompts.
+	// Prompts have to end the received data, so "Last login:" of
+	// welcome message isn't taken for a login prompt
+	PromptsV2
+)
+
+var promptV2LoginRe *regexp.Regexp = regexp.MustCompile(
+	"(?im)(?:^|[\\p{L}\\p{N}._-]+ )(?:login|username):[ \\t]*\\z")
+var promptV2PasswordRe *regexp.Regexp = regexp.MustCompile(
+	"(?i)pass(?:word|code|phrase)[^\\r\\n]*:[ \\t]*\\z")
+var promptV2BannerRe *regexp.Regexp = regexp.MustCompile(
+	"[\\p{L}\\p{N}._-]+@[\\p{L}\\p{N}._-]+:(?:[^\\s$#]|\\r\\n|\\r| \\r)*[$#][ \\t]*\\z")
+
+// defaultPrompts returns default login, password and banner regexps
+func (v PromptVersion) defaultPrompts() (login, password, banner *regexp.Regexp) {
+	if v == PromptsV2 {
+		return promptV2LoginRe, promptV2PasswordRe, promptV2BannerRe
+	}
+
+	return defaultLoginRe, defaultPasswordRe, defaultBannerRe
+}
@@ -0,0 +1,51 @@
+package telnet
+
+import (
+	"regexp"
+	"testing"
+)
+
+func Test_PromptsV2(t *testing.T) {
+	login, password, banner := PromptsV2.defaultPrompts()
+
+	tests := []struct {
+		name  string
+		re    *regexp.Regexp
+		data  string
+		match bool
+	}{
+		{"login", login, "\r\nRT-N14U login: ", true},
+		{"username", login, "User Access Verification\r\n\r\nUsername:", true},
+		{"utf-8 host login", login, "маршрутизатор-1 login: ", true},
+		{"last login", login, "Last login: Mon Jan 20 10:00:00 from 10.0.0.1\r\n", false},
+		{"password", password, "Password: ", true},
+		{"password of user", password, "admin@10.0.0.1's password:", true},
+		{"lowercase password", password, "password:", true},
+		{"password in text", password, "Password: changed\r\n", false},
+		{"banner", banner, "admin@RT-N14U:/tmp/home/root# ", true},
+		{"utf-8 banner", banner, "админ@сервер:~/документы$ ", true},
+		{"wrapped banner", banner, "admin@RT-N14U:/very/long/path/wra \rpped/by/terminal$ ", true},
+		{"banner in text", banner, "admin@RT-N14U:~$ ls\r\nfile.txt\r\n", false},
+	}
+
+	for _, tt := range tests {
+		if match := tt.re.MatchString(tt.data); match != tt.match {
+			t.Errorf("[%s] PromptsV2: unexpected match of %q: %v", tt.name, tt.data, match)
+		}
+	}
+}
+
+func Test_TelnetClient_Prompts(t *testing.T) {
+	tc := &TelnetClient{
+		Prompts:  PromptsV2,
+		BannerRe: regexp.MustCompile("R1#"),
+	}
+	tc.setDefaultParams()
+
+	if tc.LoginRe != promptV2LoginRe || tc.PasswordRe != promptV2PasswordRe {
+		t.Errorf("Prompts: defaults of version 2 aren't set")
+	}
+	if tc.BannerRe.String() != "R1#" {
+		t.Errorf("Prompts: custom BannerRe is replaced: %q", tc.BannerRe)
+	}
+}
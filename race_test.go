@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package telnet
+
+// raceEnabled is set, when tests run with race detector,
+// which changes allocations
+const raceEnabled = true
@@ -0,0 +1,81 @@
+package telnet
+
+import "time"
+
+// tokenBucket limits rate of events. Tokens are refilled with rate
+// per second up to burst, which equals to one second of rate
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	now   func() time.Time
+	sleep func(d time.Duration)
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	burst := rate
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// wait takes n tokens and waits, until they are refilled, if it's needed
+func (b *tokenBucket) wait(n int) {
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	b.tokens -= float64(n)
+	if b.tokens < 0 {
+		delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+		b.sleep(delay)
+		b.tokens = 0
+		b.last = now.Add(delay)
+	}
+}
+
+// limitWrite waits for WriteRate before sending up to n bytes and
+// returns number of bytes, which can be sent now. It isn't more
+// than burst, so large data isn't sent at once after long delay
+func (tc *TelnetClient) limitWrite(n int) int {
+	if tc.WriteRate <= 0 {
+		return n
+	}
+	if tc.writeLimiter == nil {
+		tc.writeLimiter = newTokenBucket(float64(tc.WriteRate))
+	}
+
+	if burst := int(tc.writeLimiter.burst); n > burst {
+		n = burst
+	}
+	tc.writeLimiter.wait(n)
+
+	return n
+}
+
+// limitCommand waits for CommandRate before sending command
+func (tc *TelnetClient) limitCommand() {
+	if tc.CommandRate <= 0 {
+		return
+	}
+	if tc.commandLimiter == nil {
+		tc.commandLimiter = newTokenBucket(tc.CommandRate)
+	}
+
+	tc.commandLimiter.wait(1)
+}
@@ -0,0 +1,101 @@
+package telnet
+
+import (
+	"bufio"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_tokenBucket(t *testing.T) {
+	tests := []struct {
+		name   string
+		rate   float64
+		events []int
+		pauses []time.Duration
+		want   []time.Duration
+	}{
+		{
+			name:   "tokenBucket: burst isn't delayed",
+			rate:   100,
+			events: []int{50, 50},
+			pauses: []time.Duration{0, 0},
+			want:   nil,
+		},
+		{
+			name:   "tokenBucket: exceeded burst is delayed",
+			rate:   100,
+			events: []int{100, 50, 10},
+			pauses: []time.Duration{0, 0, 0},
+			want:   []time.Duration{500 * time.Millisecond, 100 * time.Millisecond},
+		},
+		{
+			name:   "tokenBucket: tokens are refilled",
+			rate:   2,
+			events: []int{2, 1},
+			pauses: []time.Duration{0, 250 * time.Millisecond},
+			want:   []time.Duration{250 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var delays []time.Duration
+			now := time.Unix(0, 0)
+
+			b := newTokenBucket(tt.rate)
+			b.now = func() time.Time { return now }
+			b.sleep = func(d time.Duration) {
+				delays = append(delays, d)
+				now = now.Add(d)
+			}
+
+			for i, n := range tt.events {
+				now = now.Add(tt.pauses[i])
+				b.wait(n)
+			}
+
+			if !reflect.DeepEqual(delays, tt.want) {
+				t.Errorf("[%s] wrong delays:\n\t\tfact = %v\n\t\twant = %v", tt.name, delays, tt.want)
+			}
+		})
+	}
+}
+
+// chunkWriter records sizes of written chunks
+type chunkWriter struct {
+	chunks []int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.chunks = append(w.chunks, len(p))
+	return len(p), nil
+}
+
+func Test_TelnetClient_WriteRate(t *testing.T) {
+	var delays []time.Duration
+	now := time.Unix(0, 0)
+
+	sent := &chunkWriter{}
+	tc := &TelnetClient{
+		WriteRate: 4,
+		writer:    bufio.NewWriter(sent),
+	}
+	tc.writeLimiter = newTokenBucket(4)
+	tc.writeLimiter.now = func() time.Time { return now }
+	tc.writeLimiter.sleep = func(d time.Duration) {
+		delays = append(delays, d)
+		now = now.Add(d)
+	}
+
+	n, err := tc.Write([]byte("show version"))
+	if err != nil || n != 12 {
+		t.Fatalf("WriteRate: unexpected result of Write: %d, %v", n, err)
+	}
+
+	if want := []int{4, 4, 4}; !reflect.DeepEqual(sent.chunks, want) {
+		t.Errorf("WriteRate: wrong chunks:\n\t\tfact = %v\n\t\twant = %v", sent.chunks, want)
+	}
+	if want := []time.Duration{time.Second, time.Second}; !reflect.DeepEqual(delays, want) {
+		t.Errorf("WriteRate: wrong delays:\n\t\tfact = %v\n\t\twant = %v", delays, want)
+	}
+}
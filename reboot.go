@@ -0,0 +1,111 @@
+package telnet
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultRebootSilence      = 5 * time.Second
+	defaultRebootPollInterval = 5 * time.Second
+	defaultRebootTimeout      = 10 * time.Minute
+)
+
+// ErrRebootTimeout is returned by WaitForReboot, when device
+// doesn't answer again within timeout
+var ErrRebootTimeout = errors.New("telnet: device hasn't come back after reboot")
+
+// RebootOptions configures WaitForReboot
+type RebootOptions struct {
+	// Command reboots device, e.g. "reload" or "reboot"
+	Command string
+	// Silence is a pause of output, after which device is considered
+	// rebooting (5s by default)
+	Silence time.Duration
+	// PollInterval is a pause between attempts to reconnect (5s by default)
+	PollInterval time.Duration
+	// Timeout limits whole reboot (10m by default)
+	Timeout time.Duration
+	// DialOptions are passed to Dial on reconnection
+	DialOptions []DialOption
+}
+
+// WaitForReboot sends reboot command, tolerates dropped connection
+// and reconnects until device answers again and login is completed.
+// Confirmation questions are answered with AutoConfirm.
+// Returns time passed since reboot command has been sent
+func (tc *TelnetClient) WaitForReboot(opts RebootOptions) (downtime time.Duration, err error) {
+	defer tc.use()()
+
+	if opts.Silence == 0 {
+		opts.Silence = defaultRebootSilence
+	}
+	if opts.PollInterval == 0 {
+		opts.PollInterval = defaultRebootPollInterval
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultRebootTimeout
+	}
+
+	start := time.Now()
+	err = tc.ExecuteNoWait(opts.Command)
+	if err != nil {
+		return
+	}
+	err = tc.confirmReboot(opts.Silence)
+	if err != nil {
+		return
+	}
+	tc.Close()
+
+	for {
+		time.Sleep(opts.PollInterval)
+
+		tc.log("Trying to reconnect after reboot")
+		err = tc.Dial(opts.DialOptions...)
+		if err == nil {
+			downtime = time.Since(start)
+			tc.log("Device is back after %v", downtime)
+			return
+		}
+		tc.Close()
+
+		if time.Since(start) > opts.Timeout {
+			return time.Since(start), fmt.Errorf("%w: %v", ErrRebootTimeout, err)
+		}
+	}
+}
+
+// confirmReboot reads output until server is quiet for silence or drops
+// connection, answering confirmation questions of AutoConfirm
+func (tc *TelnetClient) confirmReboot(silence time.Duration) error {
+	for {
+		output, err := tc.drain(silence, time.Time{})
+		if isDropped(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		answered := false
+		for re, answer := range tc.AutoConfirm {
+			if re.Match(output) {
+				tc.log("Found confirmation %q, answer %q", re.Find(output), answer)
+				_, err = tc.write([]byte(answer + "\r\n"))
+				if isDropped(err) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				answered = true
+				break
+			}
+		}
+		if !answered {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package telnet
+
+import (
+	"net"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_WaitForReboot(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("WaitForReboot: can't listen: %v", err)
+	}
+	defer ln.Close()
+
+	// server
+	go func() {
+		buf := make([]byte, 64)
+
+		// before reboot
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("R1 login: "))
+		conn.Read(buf)
+		conn.Write([]byte("admin@R1:~$ "))
+		conn.Read(buf)
+		conn.Write([]byte("Proceed with reload? [confirm]"))
+		conn.Read(buf)
+		conn.Write([]byte("\r\nRebooting...\r\n"))
+		conn.Close()
+
+		// device is booting, login isn't available yet
+		conn, err = ln.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("Loading kernel...\r\n"))
+		time.Sleep(100 * time.Millisecond)
+		conn.Close()
+
+		// device is back
+		conn, err = ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("R1 login: "))
+		conn.Read(buf)
+		conn.Write([]byte("admin@R1:~$ "))
+		conn.Read(buf)
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	tc := &TelnetClient{
+		Address:      host,
+		Port:         port,
+		Login:        "admin",
+		ReadTimeout:  time.Second,
+		LoginTimeout: 50 * time.Millisecond,
+		AutoConfirm: map[*regexp.Regexp]string{
+			regexp.MustCompile("\\[confirm\\]"): "",
+		},
+	}
+	if err = tc.Dial(); err != nil {
+		t.Fatalf("WaitForReboot: unexpected error of Dial: %v", err)
+	}
+	defer tc.Close()
+
+	downtime, err := tc.WaitForReboot(RebootOptions{
+		Command:      "reload",
+		Silence:      50 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+		Timeout:      5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("WaitForReboot: unexpected error: %v", err)
+	}
+	if downtime <= 0 || downtime > 5*time.Second {
+		t.Errorf("WaitForReboot: wrong downtime %v", downtime)
+	}
+}
@@ -0,0 +1,153 @@
+package telnet
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Result is a structured result of command execution
+type Result struct {
+	Command string
+	// Raw is output as it is received, including echo and prompt
+	Raw []byte
+	// Output is output without prompt and echo, as Execute returns it
+	Output []byte
+	// Prompt is the matched banner
+	Prompt []byte
+	Start  time.Time
+	End    time.Time
+	// BytesRead is a number of data bytes received
+	BytesRead int
+}
+
+// Duration returns time of command execution
+func (r *Result) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// resultJSON is JSON representation of Result with stable field names.
+// Outputs are strings, so they are readable by other tools
+type resultJSON struct {
+	Command    string    `json:"command"`
+	Output     string    `json:"output"`
+	Raw        string    `json:"raw"`
+	Prompt     string    `json:"prompt"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	DurationMS float64   `json:"duration_ms"`
+	BytesRead  int       `json:"bytes_read"`
+}
+
+// MarshalJSON encodes outputs as strings instead of base64,
+// invalid UTF-8 is replaced with U+FFFD
+func (r Result) MarshalJSON() ([]byte, error) {
+	return json.Marshal(resultJSON{
+		Command:    r.Command,
+		Output:     string(r.Output),
+		Raw:        string(r.Raw),
+		Prompt:     string(r.Prompt),
+		Start:      r.Start,
+		End:        r.End,
+		DurationMS: float64(r.Duration()) / float64(time.Millisecond),
+		BytesRead:  r.BytesRead,
+	})
+}
+
+// UnmarshalJSON decodes result encoded by MarshalJSON.
+// Duration isn't decoded, it is computed from Start and End
+func (r *Result) UnmarshalJSON(data []byte) (err error) {
+	var v resultJSON
+	err = json.Unmarshal(data, &v)
+	if err != nil {
+		return
+	}
+
+	*r = Result{
+		Command:   v.Command,
+		Output:    []byte(v.Output),
+		Raw:       []byte(v.Raw),
+		Prompt:    []byte(v.Prompt),
+		Start:     v.Start,
+		End:       v.End,
+		BytesRead: v.BytesRead,
+	}
+
+	return
+}
+
+// ExecuteResult works like Execute, but returns output
+// along with matched prompt and timing. Result is returned
+// on failure too, it keeps output received so far
+func (tc *TelnetClient) ExecuteResult(
+	name string,
+	args ...string,
+) (result *Result, err error) {
+	tc.execMu.Lock()
+	defer tc.execMu.Unlock()
+	defer tc.markActive()
+
+	return tc.executeResult(name, args...)
+}
+
+// executeResult executes command, caller holds execMu
+func (tc *TelnetClient) executeResult(
+	name string,
+	args ...string,
+) (result *Result, err error) {
+	request := commandRequest(name, args)
+	result = &Result{
+		Command: string(request[:len(request)-2]),
+		Start:   time.Now(),
+	}
+	defer func() {
+		result.End = time.Now()
+	}()
+
+	err = tc.checkPolicy(result.Command)
+	if err != nil {
+		return
+	}
+	if tc.DryRun {
+		result.Output = tc.recordDryRun(result.Command)
+		result.Raw = result.Output
+		return
+	}
+
+	err = tc.discardBuffered()
+	if err != nil {
+		return
+	}
+	tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+
+	tc.limitCommand()
+	tc.trackPrompt(result.Command)
+	tc.log("Send command: %s", result.Command)
+	_, err = tc.write(tc.outgoingCR(request))
+	if err != nil {
+		return
+	}
+
+	result.Raw, result.Prompt, err = tc.readUntilBannerConfirming()
+	result.BytesRead = len(result.Raw)
+	result.Output = tc.trimBanner(result.Raw, result.Prompt)
+	if err != nil {
+		tc.log("Failed to read output, received %d bytes", len(result.Output))
+		err = &ExecuteError{
+			Command: result.Command,
+			Output:  result.Output,
+			Err:     err,
+			Tags:    tc.Tags,
+		}
+		return
+	}
+	result.Output = tc.stripEcho(result.Output, result.Command)
+	tc.log("Received data with size = %d", len(result.Output))
+
+	return
+}
+
+// commandRequest joins command and its arguments into line sent to server
+func commandRequest(name string, args []string) []byte {
+	return []byte(name + " " + strings.Join(args, " ") + "\r\n")
+}
@@ -0,0 +1,90 @@
+package telnet
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_ExecuteResult(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout: time.Second,
+		Delimiter:   defaultDelimiter,
+		BannerRe:    defaultBannerRe,
+		EchoMode:    EchoAuto,
+	}
+	tc.setConn(client)
+
+	// server
+	go func() {
+		command := make([]byte, 64)
+		server.Read(command)
+		server.Write([]byte("uptime \r\nup 3 days\r\nadmin@R1:~$ "))
+	}()
+
+	result, err := tc.ExecuteResult("uptime")
+	if err != nil {
+		t.Fatalf("ExecuteResult: unexpected error: %v", err)
+	}
+
+	for _, c := range []struct {
+		name string
+		fact string
+		want string
+	}{
+		{"Command", result.Command, "uptime "},
+		{"Raw", string(result.Raw), "uptime \r\nup 3 days\r\nadmin@R1:~$"},
+		{"Output", string(result.Output), "up 3 days\r\n"},
+		{"Prompt", string(result.Prompt), "admin@R1:~$"},
+	} {
+		if c.fact != c.want {
+			t.Errorf("ExecuteResult: wrong %s:\n\t\tfact = %q\n\t\twant = %q",
+				c.name, c.fact, c.want)
+		}
+	}
+	if result.BytesRead != len(result.Raw) {
+		t.Errorf("ExecuteResult: wrong BytesRead %d", result.BytesRead)
+	}
+	if result.Start.IsZero() || result.Duration() < 0 {
+		t.Errorf("ExecuteResult: wrong timing %v - %v", result.Start, result.End)
+	}
+}
+
+func Test_Result_JSON(t *testing.T) {
+	start := time.Date(2020, 1, 19, 14, 13, 45, 0, time.UTC)
+	result := Result{
+		Command:   "uptime",
+		Raw:       []byte("uptime\r\nup 3 days\r\nR1#"),
+		Output:    []byte("up 3 days\r\n"),
+		Prompt:    []byte("R1#"),
+		Start:     start,
+		End:       start.Add(1500 * time.Microsecond),
+		BytesRead: 22,
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Result JSON: unexpected error: %v", err)
+	}
+
+	want := `{"command":"uptime","output":"up 3 days\r\n","raw":"uptime\r\nup 3 days\r\nR1#",` +
+		`"prompt":"R1#","start":"2020-01-19T14:13:45Z","end":"2020-01-19T14:13:45.0015Z",` +
+		`"duration_ms":1.5,"bytes_read":22}`
+	if string(data) != want {
+		t.Errorf("Result JSON: wrong encoding:\n\t\tfact = %s\n\t\twant = %s", data, want)
+	}
+
+	var decoded Result
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Result JSON: unexpected error of decoding: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, result) {
+		t.Errorf("Result JSON: wrong decoding:\n\t\tfact = %+v\n\t\twant = %+v", decoded, result)
+	}
+}
@@ -0,0 +1,67 @@
+package telnet
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Render renders command template (text/template syntax, e.g.
+// "ping {{.Target}}") with data. Missing keys of data map are errors,
+// so incomplete data doesn't produce broken commands
+func Render(text string, data interface{}) (string, error) {
+	tmpl, err := template.New("command").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("telnet: wrong template %q: %w", text, err)
+	}
+
+	var b strings.Builder
+	err = tmpl.Execute(&b, data)
+	if err != nil {
+		return "", fmt.Errorf("telnet: can't render template %q: %w", text, err)
+	}
+
+	return b.String(), nil
+}
+
+// RunScript renders commands with data and executes them in order,
+// so one script can be reused across devices. It stops at the first
+// failed command and returns outputs of executed commands
+func (tc *TelnetClient) RunScript(commands []string, data interface{}) (outputs [][]byte, err error) {
+	rendered := make([]string, 0, len(commands))
+	for _, command := range commands {
+		var line string
+		line, err = Render(command, data)
+		if err != nil {
+			return
+		}
+		rendered = append(rendered, line)
+	}
+
+	for _, command := range rendered {
+		var output []byte
+		output, err = tc.Execute(command)
+		outputs = append(outputs, output)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// RenderPatterns renders Send of patterns with data,
+// so dialog of ExpectWithTimeout can be reused across devices
+func RenderPatterns(patterns []Pattern, data interface{}) ([]Pattern, error) {
+	rendered := make([]Pattern, len(patterns))
+	for i, pattern := range patterns {
+		send, err := Render(pattern.Send, data)
+		if err != nil {
+			return nil, err
+		}
+		pattern.Send = send
+		rendered[i] = pattern
+	}
+
+	return rendered, nil
+}
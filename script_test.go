@@ -0,0 +1,59 @@
+package telnet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Render(t *testing.T) {
+	data := map[string]interface{}{"Target": "10.0.0.1", "Count": 5}
+
+	tests := []struct {
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"ping {{.Target}} repeat {{.Count}}", "ping 10.0.0.1 repeat 5", false},
+		{"show clock", "show clock", false},
+		{"ping {{.Source}}", "", true},
+		{"ping {{.Target}", "", true},
+	}
+
+	for _, tt := range tests {
+		command, err := Render(tt.text, data)
+		if command != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("Render: unexpected result of %q: %q, %v", tt.text, command, err)
+		}
+	}
+}
+
+func Test_TelnetClient_RunScript(t *testing.T) {
+	script := []string{"ping {{.Target}}", "traceroute {{.Target}}"}
+
+	for _, target := range []string{"10.0.0.1", "10.0.0.2"} {
+		tc := &TelnetClient{DryRun: true}
+		outputs, err := tc.RunScript(script, map[string]string{"Target": target})
+		if err != nil || len(outputs) != 2 {
+			t.Fatalf("RunScript: unexpected result: %d outputs, %v", len(outputs), err)
+		}
+
+		want := []string{"ping " + target, "traceroute " + target}
+		if !reflect.DeepEqual(tc.DryRunCommands(), want) {
+			t.Errorf("RunScript: wrong commands:\n\t\tfact = %q\n\t\twant = %q", tc.DryRunCommands(), want)
+		}
+	}
+
+	tc := &TelnetClient{DryRun: true}
+	if _, err := tc.RunScript(script, map[string]string{}); err == nil || len(tc.DryRunCommands()) != 0 {
+		t.Errorf("RunScript: commands are sent with missing data: %q, %v", tc.DryRunCommands(), err)
+	}
+}
+
+func Test_RenderPatterns(t *testing.T) {
+	patterns, err := RenderPatterns(
+		[]Pattern{{Send: "{{.Password}}\r\n"}, {Finish: true}},
+		map[string]string{"Password": "secret"})
+	if err != nil || patterns[0].Send != "secret\r\n" || !patterns[1].Finish {
+		t.Errorf("RenderPatterns: unexpected result: %+v, %v", patterns, err)
+	}
+}
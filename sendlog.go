@@ -0,0 +1,31 @@
+package telnet
+
+import "bytes"
+
+// redactedPassword replaces password in send logs
+var redactedPassword = []byte("******")
+
+// logSend logs data sent to server and passes it to OnSend hook
+func (tc *TelnetClient) logSend(data []byte) {
+	if !tc.Verbose && tc.OnSend == nil {
+		return
+	}
+
+	redacted := tc.redact(data)
+	tc.log("Send %d bytes: %q", len(data), redacted)
+	if tc.OnSend != nil {
+		tc.OnSend(redacted)
+	}
+}
+
+// redact hides sensitive data with Redact function or hides Password
+func (tc *TelnetClient) redact(data []byte) []byte {
+	if tc.Redact != nil {
+		return tc.Redact(data)
+	}
+	if tc.Password == "" {
+		return data
+	}
+
+	return bytes.Replace(data, []byte(tc.Password), redactedPassword, -1)
+}
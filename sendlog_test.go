@@ -0,0 +1,60 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func Test_TelnetClient_OnSend(t *testing.T) {
+	tests := []struct {
+		name   string
+		redact func(data []byte) []byte
+		want   []string
+	}{
+		{
+			name: "OnSend: password is redacted by default",
+			want: []string{"admin\r\n", "******\r\n", "\xff\xfb\x1f"},
+		},
+		{
+			name: "OnSend: custom redaction",
+			redact: func(data []byte) []byte {
+				return regexp.MustCompile("admin").ReplaceAll(data, []byte("<user>"))
+			},
+			want: []string{"<user>\r\n", "P@ssw0rd\r\n", "\xff\xfb\x1f"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			logs := &bytes.Buffer{}
+
+			tc := &TelnetClient{
+				Password:  "P@ssw0rd",
+				Verbose:   true,
+				LogWriter: bufio.NewWriter(logs),
+				writer:    bufio.NewWriter(&bytes.Buffer{}),
+				Redact:    tt.redact,
+				OnSend: func(data []byte) {
+					sent = append(sent, string(data))
+				},
+			}
+			tc.Write([]byte("admin\r\n"))
+			tc.Write([]byte("P@ssw0rd\r\n"))
+			tc.Write(negotiation(WILL, OptNAWS))
+
+			if !reflect.DeepEqual(sent, tt.want) {
+				t.Errorf("[%s] wrong data:\n\t\tfact = %q\n\t\twant = %q", tt.name, sent, tt.want)
+			}
+			if tt.redact == nil && strings.Contains(logs.String(), "P@ssw0rd") {
+				t.Errorf("[%s] password isn't redacted in log:\n%s", tt.name, logs.String())
+			}
+			if !strings.Contains(logs.String(), "telnet: Send 3 bytes: \"\\xff\\xfb\\x1f\"\n") {
+				t.Errorf("[%s] negotiation isn't logged:\n%s", tt.name, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package telnet
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ResumeProfile is a minimal state of session, which can be saved
+// (e.g. as JSON) and used by another process to establish
+// an equivalent session with RestoreSession
+type ResumeProfile struct {
+	Address     string        `json:"address"`
+	Port        string        `json:"port,omitempty"`
+	Login       string        `json:"login,omitempty"`
+	ConnTimeout time.Duration `json:"conn_timeout,omitempty"`
+	ReadTimeout time.Duration `json:"read_timeout,omitempty"`
+	// LoginPrompt, PasswordPrompt and Prompt are regular expressions
+	// of login, password prompts and banner learned by the session
+	LoginPrompt    string   `json:"login_prompt,omitempty"`
+	PasswordPrompt string   `json:"password_prompt,omitempty"`
+	Prompt         string   `json:"prompt,omitempty"`
+	InitCommands   []string `json:"init_commands,omitempty"`
+
+	// Password isn't serialized, it has to be set before RestoreSession
+	Password string `json:"-"`
+}
+
+// ResumeProfile returns state of session required to restore it
+func (tc *TelnetClient) ResumeProfile() ResumeProfile {
+	profile := ResumeProfile{
+		Address:      tc.Address,
+		Port:         tc.Port,
+		Login:        tc.Login,
+		ConnTimeout:  tc.ConnTimeout,
+		ReadTimeout:  tc.ReadTimeout,
+		InitCommands: tc.InitCommands,
+	}
+	if tc.LoginRe != nil {
+		profile.LoginPrompt = tc.LoginRe.String()
+	}
+	if tc.PasswordRe != nil {
+		profile.PasswordPrompt = tc.PasswordRe.String()
+	}
+	if tc.BannerRe != nil {
+		profile.Prompt = tc.BannerRe.String()
+	}
+
+	return profile
+}
+
+// RestoreSession dials server, logs in and executes init commands
+// of the profile in one step
+func RestoreSession(profile ResumeProfile) (tc *TelnetClient, err error) {
+	tc = &TelnetClient{
+		Address:      profile.Address,
+		Port:         profile.Port,
+		Login:        profile.Login,
+		Password:     profile.Password,
+		ConnTimeout:  profile.ConnTimeout,
+		ReadTimeout:  profile.ReadTimeout,
+		InitCommands: profile.InitCommands,
+	}
+
+	for _, p := range []struct {
+		re      **regexp.Regexp
+		pattern string
+	}{
+		{&tc.LoginRe, profile.LoginPrompt},
+		{&tc.PasswordRe, profile.PasswordPrompt},
+		{&tc.BannerRe, profile.Prompt},
+	} {
+		if p.pattern == "" {
+			continue
+		}
+		*p.re, err = regexp.Compile(p.pattern)
+		if err != nil {
+			return nil, fmt.Errorf("telnet: invalid prompt in profile: %w", err)
+		}
+	}
+
+	err = tc.Dial()
+	if err != nil {
+		if tc.conn != nil {
+			tc.Close()
+		}
+		return nil, err
+	}
+
+	return
+}
@@ -0,0 +1,78 @@
+package telnet
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_RestoreSession(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("RestoreSession: can't listen: %v", err)
+	}
+	defer ln.Close()
+
+	// server
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var lines []string
+		buf := make([]byte, 64)
+		for _, prompt := range []string{
+			"R1 login:", "Password:", "admin@R1:~$ ", "\r\nadmin@R1:~$ ",
+		} {
+			conn.Write([]byte(prompt))
+			if len(lines) == 3 {
+				break
+			}
+			n, _ := conn.Read(buf)
+			lines = append(lines, string(buf[:n]))
+		}
+		received <- lines
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	origin := &TelnetClient{
+		Address:      host,
+		Port:         port,
+		Login:        "admin",
+		Password:     "secret",
+		ReadTimeout:  time.Second,
+		InitCommands: []string{"terminal length 0"},
+	}
+	origin.setDefaultParams()
+
+	data, err := json.Marshal(origin.ResumeProfile())
+	if err != nil {
+		t.Fatalf("RestoreSession: can't marshal profile: %v", err)
+	}
+	var profile ResumeProfile
+	if err = json.Unmarshal(data, &profile); err != nil {
+		t.Fatalf("RestoreSession: can't unmarshal profile: %v", err)
+	}
+	if profile.Password != "" {
+		t.Errorf("RestoreSession: password is serialized: %s", data)
+	}
+	profile.Password = "secret"
+
+	tc, err := RestoreSession(profile)
+	if err != nil {
+		t.Fatalf("RestoreSession: unexpected error: %v", err)
+	}
+	defer tc.Close()
+
+	want := []string{"admin\r\n", "secret\r\n", "terminal length 0 \r\n"}
+	if lines := <-received; !reflect.DeepEqual(lines, want) {
+		t.Errorf(
+			"RestoreSession: wrong data received by server:\n\t\tfact = %q\n\t\twant = %q",
+			lines, want)
+	}
+}
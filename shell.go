@@ -0,0 +1,73 @@
+package telnet
+
+import (
+	"io"
+	"os"
+	"os/signal"
+)
+
+// Shell runs interactive session: stdin is passed to server,
+// output is written to stdout. If stdin is a terminal, it is switched
+// to raw mode and its size changes are sent to server via NAWS
+func (tc *TelnetClient) Shell(stdin *os.File, stdout io.Writer) (err error) {
+	defer tc.use()()
+
+	fd := int(stdin.Fd())
+
+	if IsTerminal(fd) {
+		var state *TerminalState
+
+		state, err = MakeRaw(fd)
+		if err != nil {
+			return
+		}
+		defer RestoreTerminal(fd, state)
+
+		if width, height, err := TerminalSize(fd); err == nil {
+			tc.windowSize = [2]uint16{uint16(width), uint16(height)}
+		}
+
+		resize := make(chan os.Signal, 1)
+		done := make(chan struct{})
+		notifyResize(resize)
+		defer func() {
+			signal.Stop(resize)
+			close(done)
+		}()
+		go tc.watchResize(fd, resize, done)
+	}
+
+	if tc.windowSize != [2]uint16{} {
+		if _, ok := tc.localOptions[OptNAWS]; ok {
+			err = tc.SetWindowSize(int(tc.windowSize[0]), int(tc.windowSize[1]))
+		} else {
+			err = tc.requestLocal(OptNAWS, tc.sendWindowSize)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return tc.Bridge(struct {
+		io.Reader
+		io.Writer
+	}{stdin, stdout})
+}
+
+// watchResize sends new terminal size on each resize signal
+func (tc *TelnetClient) watchResize(fd int, resize <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case <-resize:
+			width, height, err := TerminalSize(fd)
+			if err != nil {
+				continue
+			}
+			if err = tc.SetWindowSize(width, height); err != nil {
+				tc.log("Failed to send window size: %v", err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
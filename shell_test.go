@@ -0,0 +1,58 @@
+package telnet
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_Shell(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	stdin, input, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Shell: can't create pipe: %v", err)
+	}
+	defer stdin.Close()
+	stdout := &bytes.Buffer{}
+
+	tc := &TelnetClient{ReadTimeout: time.Second}
+	tc.setConn(client)
+	tc.SetWindowSize(80, 24)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tc.Shell(stdin, stdout)
+	}()
+
+	// NAWS is offered, as stdin isn't a terminal and size is set
+	want := []byte{
+		0xff, 0xfb, 0x1f,
+		0xff, 0xfa, 0x1f, 0x00, 0x50, 0x00, 0x18, 0xff, 0xf0,
+		'l', 's', '\r', '\n',
+	}
+	go input.Write([]byte("ls\r\n"))
+
+	got := make([]byte, 0, len(want))
+	buf := make([]byte, 32)
+	for len(got) < len(want) {
+		n, err := server.Read(buf)
+		if err != nil {
+			t.Fatalf("Shell: unexpected error of session: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if bytes.Compare(got, want) != 0 {
+		t.Errorf("Shell: wrong input:\n\t\tfact = % x\n\t\twant = % x", got, want)
+	}
+
+	input.Close()
+	if err := <-done; err != nil && err != io.EOF {
+		t.Errorf("Shell: unexpected error: %v", err)
+	}
+	tc.Close()
+}
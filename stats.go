@@ -0,0 +1,33 @@
+package telnet
+
+// ProtocolStats counts telnet protocol traffic, which is consumed
+// by client and isn't passed to output. Growing counters without output
+// show, that client is busy with broken negotiation of server
+type ProtocolStats struct {
+	// Tags of session, so stats of concurrent sessions can be told apart
+	Tags map[string]string `json:"tags,omitempty"`
+	// Commands is a number of received IAC commands
+	Commands int `json:"commands"`
+	// RefusedOptions is a number of negotiation requests
+	// of options, which client doesn't accept
+	RefusedOptions int `json:"refused_options"`
+	// SkippedPayload is a number of subnegotiation payload bytes,
+	// which aren't handled by client or OnSubnegotiation
+	SkippedPayload int `json:"skipped_payload"`
+	// UnexpectedBytes is a number of unknown commands
+	// and commands not allowed inside of subnegotiation
+	UnexpectedBytes int `json:"unexpected_bytes"`
+}
+
+// Stats returns protocol statistics of the current connection
+func (tc *TelnetClient) Stats() ProtocolStats {
+	stats := tc.stats
+	if len(tc.Tags) > 0 {
+		stats.Tags = make(map[string]string, len(tc.Tags))
+		for key, value := range tc.Tags {
+			stats.Tags[key] = value
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,38 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func Test_TelnetClient_Stats(t *testing.T) {
+	tc := &TelnetClient{
+		Tags: map[string]string{"device": "R1"},
+		reader: bufio.NewReader(bytes.NewReader([]byte(
+			"\xff\xfb\x01" + // WILL ECHO
+				"\xff\xfd\x18" + // DO TTYPE
+				"\xff\xfa\x18\x01\xff\xf0" + // SB TTYPE SEND SE
+				"\xff\xfa\x18\x01\xff\xf1\xff\xf0" + // NOP inside of SB
+				"\xff\xfc\x01" + // WONT ECHO
+				"R1#"))),
+		writer: bufio.NewWriter(&bytes.Buffer{}),
+	}
+
+	var output []byte
+	if _, err := tc.ReadUntil(&output, '#'); err != nil {
+		t.Fatalf("Stats: unexpected error: %v", err)
+	}
+
+	want := ProtocolStats{
+		Tags:            map[string]string{"device": "R1"},
+		Commands:        5,
+		RefusedOptions:  2,
+		SkippedPayload:  2,
+		UnexpectedBytes: 1,
+	}
+	if stats := tc.Stats(); !reflect.DeepEqual(stats, want) {
+		t.Errorf("Stats: wrong stats:\n\t\tfact = %+v\n\t\twant = %+v", stats, want)
+	}
+}
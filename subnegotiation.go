@@ -0,0 +1,48 @@
+package telnet
+
+// Subnegotiation commands of options, which report client values
+// (TERMINAL-SPEED, X-DISPLAY-LOCATION, TERMINAL-TYPE)
+const (
+	sbIS   = 0
+	sbSEND = 1
+)
+
+// SubnegotiationHandler receives payload of subnegotiation
+// with IAC IAC sequences unescaped
+type SubnegotiationHandler func(option Option, data []byte)
+
+// handleSubnegotiation passes payload of subnegotiation to handler
+func (tc *TelnetClient) handleSubnegotiation(option Option, data []byte) {
+	tc.trace("recv IAC SB %s [% x] IAC SE", option, data)
+
+	handler, ok := tc.sbHandlers[option]
+	if ok {
+		handler(data)
+	}
+	if tc.OnSubnegotiation != nil {
+		tc.OnSubnegotiation(option, data)
+	} else if !ok {
+		tc.stats.SkippedPayload += len(data)
+	}
+}
+
+// SendSubnegotiation sends IAC SB option data IAC SE sequence.
+// IAC bytes in data are escaped
+func (tc *TelnetClient) SendSubnegotiation(option Option, data []byte) (err error) {
+	defer tc.use()()
+
+	return tc.sendSubnegotiation(option, data)
+}
+
+// sendSubnegotiation sends sequence like SendSubnegotiation,
+// session isn't marked as used
+func (tc *TelnetClient) sendSubnegotiation(option Option, data []byte) (err error) {
+	sequence := make([]byte, 0, len(data)+5)
+	sequence = append(sequence, byte(IAC), byte(SB), byte(option))
+	sequence = append(sequence, escapeIAC(data)...)
+	sequence = append(sequence, byte(IAC), byte(SE))
+
+	_, err = tc.write(sequence)
+
+	return
+}
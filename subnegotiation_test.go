@@ -0,0 +1,52 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func Test_TelnetClient_OnSubnegotiation(t *testing.T) {
+	var option Option
+	var payload []byte
+
+	tc := &TelnetClient{
+		reader: bufio.NewReader(bytes.NewReader([]byte{
+			0xff, 0xfa, 0xc9, 'a', 0xff, 0xff, 0xf0, 'b', 0xff, 0xf0,
+			'$',
+		})),
+		OnSubnegotiation: func(o Option, data []byte) {
+			option, payload = o, data
+		},
+	}
+
+	b, err := tc.ReadByte()
+	if err != nil || b != '$' {
+		t.Fatalf("OnSubnegotiation: unexpected result of ReadByte: %v, %v", b, err)
+	}
+	if option != OptGMCP {
+		t.Errorf("OnSubnegotiation: wrong option %s", option)
+	}
+	if want := []byte{'a', 0xff, 0xf0, 'b'}; bytes.Compare(payload, want) != 0 {
+		t.Errorf(
+			"OnSubnegotiation: wrong payload:\n\t\tfact = %v\n\t\twant = %v",
+			payload, want)
+	}
+}
+
+func Test_TelnetClient_SendSubnegotiation(t *testing.T) {
+	sent := &bytes.Buffer{}
+	tc := &TelnetClient{writer: bufio.NewWriter(sent)}
+
+	err := tc.SendSubnegotiation(OptNAWS, []byte{0, 0xff, 0, 24})
+	if err != nil {
+		t.Fatalf("SendSubnegotiation: unexpected error: %v", err)
+	}
+
+	want := []byte{0xff, 0xfa, 0x1f, 0, 0xff, 0xff, 0, 24, 0xff, 0xf0}
+	if bytes.Compare(sent.Bytes(), want) != 0 {
+		t.Errorf(
+			"SendSubnegotiation: wrong data:\n\t\tfact = %v\n\t\twant = %v",
+			sent.Bytes(), want)
+	}
+}
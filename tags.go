@@ -0,0 +1,41 @@
+package telnet
+
+import (
+	"sort"
+	"strings"
+)
+
+// Tag attaches metadata to session, e.g. Tag("device", "R1")
+func (tc *TelnetClient) Tag(key, value string) {
+	if tc.Tags == nil {
+		tc.Tags = make(map[string]string)
+	}
+
+	tc.Tags[key] = value
+}
+
+// formatTags formats tags as "[key=value ...] " sorted by keys,
+// so lines of concurrent sessions can be told apart
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(key + "=" + tags[key])
+	}
+	b.WriteString("] ")
+
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+)
+
+func Test_TelnetClient_Tag(t *testing.T) {
+	logs := &bytes.Buffer{}
+	tc := &TelnetClient{
+		Verbose:   true,
+		LogWriter: bufio.NewWriter(logs),
+	}
+	tc.Tag("site", "ams1")
+	tc.Tag("device", "R1")
+
+	tc.log("Found login prompt")
+	if want := "telnet: [device=R1 site=ams1] Found login prompt\n"; logs.String() != want {
+		t.Errorf("Tag: wrong log line:\n\t\tfact = %q\n\t\twant = %q", logs.String(), want)
+	}
+
+	err := &ExecuteError{Command: "uptime", Err: io.EOF, Tags: tc.Tags}
+	want := `telnet: [device=R1 site=ams1] execute "uptime" failed after 0 bytes of output: EOF`
+	if err.Error() != want {
+		t.Errorf("Tag: wrong error:\n\t\tfact = %q\n\t\twant = %q", err.Error(), want)
+	}
+}
+
+func Test_TelnetClient_TagPercent(t *testing.T) {
+	logs := &bytes.Buffer{}
+	tc := &TelnetClient{
+		Verbose:   true,
+		Trace:     true,
+		LogWriter: bufio.NewWriter(logs),
+	}
+	tc.Tag("ticket", "50%s off")
+
+	tc.log("Send %d bytes", 8)
+	tc.trace("recv IAC %s", CmdNOP)
+	want := "telnet: [ticket=50%s off] Send 8 bytes\n" +
+		"telnet: [ticket=50%s off] recv IAC NOP\n"
+	if logs.String() != want {
+		t.Errorf("Tag percent: wrong log lines:\n\t\tfact = %q\n\t\twant = %q", logs.String(), want)
+	}
+}
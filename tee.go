@@ -0,0 +1,33 @@
+package telnet
+
+import "io"
+
+// teeBufferSize is a maximum size of data mirrored in one write
+const teeBufferSize = 4 * 1024
+
+// Tee mirrors received output (after filters) to writers,
+// while normal processing proceeds, e.g. to show progress
+// of long operations. Data is written, when all received data is read
+func (tc *TelnetClient) Tee(writers ...io.Writer) {
+	tc.tee = append(tc.tee, writers...)
+}
+
+// teeByte collects received byte and writes collected data to writers,
+// when there is no more buffered data
+func (tc *TelnetClient) teeByte(b byte) {
+	if len(tc.tee) == 0 {
+		return
+	}
+
+	tc.teeBuf = append(tc.teeBuf, b)
+	if tc.buffered() > 0 && len(tc.teeBuf) < teeBufferSize {
+		return
+	}
+
+	for _, w := range tc.tee {
+		if _, err := w.Write(tc.teeBuf); err != nil {
+			tc.log("Failed to mirror output: %v", err)
+		}
+	}
+	tc.teeBuf = tc.teeBuf[:0]
+}
@@ -0,0 +1,32 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func Test_TelnetClient_Tee(t *testing.T) {
+	first := &bytes.Buffer{}
+	second := &bytes.Buffer{}
+
+	tc := &TelnetClient{
+		Delimiter: defaultDelimiter,
+		BannerRe:  defaultBannerRe,
+		Filters:   []ReadFilter{StripANSI()},
+	}
+	tc.Tee(first, second)
+	tc.reader = bufio.NewReader(bytes.NewReader([]byte(
+		"\x1b[32mcopying\x1b[0m 100%\r\n\xff\xf1admin@R1:~$ ")))
+
+	if _, err := tc.ReadUntilBanner(); err != nil {
+		t.Fatalf("Tee: unexpected error: %v", err)
+	}
+
+	want := "copying 100%\r\nadmin@R1:~$ "
+	for _, w := range []*bytes.Buffer{first, second} {
+		if w.String() != want {
+			t.Errorf("Tee: wrong mirrored output:\n\t\tfact = %q\n\t\twant = %q", w.String(), want)
+		}
+	}
+}
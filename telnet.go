@@ -3,43 +3,95 @@ package telnet
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"regexp"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-const (
-	// IAC interpret as command
-	IAC = 255
-	// SB is sub negotiation of the indicated option follows
-	SB = 250
-	// SE is end of sub negotiation parameters
-	SE = 240
-	// WILL indicate the desire to begin
-	WILL = 251
-	// WONT indicate the refusal to perform,
-	// continue performing, the indicated option
-	WONT = 252
-	// DO indicate the request that the other
-	// party perform, or confirmation that you are
-	// expecting the other party to perform, the indicated option
-	DO = 253
-	// DONT indicate the demand that the other
-	// party stop performing, or confirmation that you
-	// are no longer expecting the other party to
-	// perform, the indicated option
-	DONT = 254
-)
-
 const defaultDelimiter byte = ' '
 
+const defaultReadTimeout = 10 * time.Second
+
+// defaultMatchWindow is a default size of the stream tail,
+// where prompts are searched
+const defaultMatchWindow = 4 * 1024
+
+// initialOutputSize is initial capacity of output buffer
+const initialOutputSize = 4 * 1024
+
 var defaultLoginRe *regexp.Regexp = regexp.MustCompile("[\\w\\d-_]+ login:")
 var defaultPasswordRe *regexp.Regexp = regexp.MustCompile("Password:")
 var defaultBannerRe *regexp.Regexp = regexp.MustCompile(
 	"[\\w\\d-_]+@[\\w\\d-_]+:[\\w\\d/-_~]+(\\$|#)")
+var defaultLockoutRe *regexp.Regexp = regexp.MustCompile(
+	"(?i)account (is )?locked|locked out|too many (login |authentication )?failures")
+
+// ErrStalled is returned when remote server stops sending data
+// for longer than InactivityTimeout
+var ErrStalled = errors.New("telnet: no data received within inactivity timeout")
+
+// ErrClosed is returned by reading and writing, when client is closed
+var ErrClosed = errors.New("telnet: client is closed")
+
+// errRecordEnd signals, that prompt is marked by IAC EOR or IAC GA
+var errRecordEnd = errors.New("telnet: end of record")
+
+// ErrLoginTimeout is returned by Dial, when login isn't completed
+// within LoginTimeout
+var ErrLoginTimeout = errors.New("telnet: login timeout")
+
+// ErrLoginAttempts is returned by Dial, when server asks
+// for credentials more than MaxLoginAttempts times
+var ErrLoginAttempts = errors.New("telnet: too many login attempts")
+
+// ErrLockedOut is returned by Dial, when server reports,
+// that account is locked, so retrying is useless
+var ErrLockedOut = errors.New("telnet: account is locked out")
+
+// LoginError is returned by Dial, when welcome signs can't be read.
+// It keeps output received before the failure
+type LoginError struct {
+	Output []byte
+	Err    error
+	Tags   map[string]string
+}
+
+func (e *LoginError) Error() string {
+	return fmt.Sprintf(
+		"telnet: %slogin failed after %d bytes of output: %v",
+		formatTags(e.Tags), len(e.Output), e.Err)
+}
+
+// Unwrap returns underlying error
+func (e *LoginError) Unwrap() error {
+	return e.Err
+}
+
+// ExecuteError is returned by Execute, when command output
+// can't be read completely. It keeps output received before the failure
+type ExecuteError struct {
+	Command string
+	Output  []byte
+	Err     error
+	Tags    map[string]string
+}
+
+func (e *ExecuteError) Error() string {
+	return fmt.Sprintf(
+		"telnet: %sexecute %q failed after %d bytes of output: %v",
+		formatTags(e.Tags), e.Command, len(e.Output), e.Err)
+}
+
+// Unwrap returns underlying error
+func (e *ExecuteError) Unwrap() error {
+	return e.Err
+}
 
 // TelnetClient is basic descriptor
 type TelnetClient struct {
@@ -50,15 +102,189 @@ type TelnetClient struct {
 	ConnTimeout time.Duration
 	ReadTimeout time.Duration
 	Verbose     bool
+	Trace       bool
 	LogWriter   *bufio.Writer
 	reader      *bufio.Reader
 	writer      *bufio.Writer
-	conn        net.Conn
+	writeMu     sync.Mutex
+	conn        Transport
+	closed      int32
+	inflater    *inflateReader
+	inflating   int32
+
+	// Tags are metadata of session (device name, site, ticket ID),
+	// which are included in log lines, errors and statistics
+	Tags map[string]string
+
+	// Addresses are tried by Dial in order after Address,
+	// e.g. secondary management IPs or console servers.
+	// Addresses without port use Port
+	Addresses        []string
+	connectedAddress string
+	dialTrace        *DialTrace
+
+	// LoginTimeout limits waiting for welcome signs and login in Dial,
+	// so slow-booting devices don't require long ReadTimeout of commands.
+	// Zero means ReadTimeout is used
+	LoginTimeout time.Duration
+
+	// MaxLoginAttempts limits sending of credentials in Dial, so wrong
+	// ones don't lock out account. Zero means unlimited.
+	// LockoutRe matches lockout messages (default is "Account locked",
+	// "Too many failures" and similar)
+	MaxLoginAttempts int
+	LockoutRe        *regexp.Regexp
+
+	// InactivityTimeout limits a pause between received pieces of data,
+	// while ReadTimeout limits whole reading of command output.
+	// Zero means the pause is limited by ReadTimeout only
+	InactivityTimeout time.Duration
+	deadline          time.Time
+
+	channels      map[string]*Channel
+	activeChannel *Channel
+
+	// Filters transform incoming data before matching of prompts
+	Filters  []ReadFilter
+	filtered []byte
+	pushback []byte
+	afterCR  bool
+
+	// OnSubnegotiation receives payloads of subnegotiations sent by server
+	OnSubnegotiation SubnegotiationHandler
+	sbHandlers       map[Option]func(data []byte)
+	remoteOptions    map[Option]*optionState
+	localOptions     map[Option]*optionState
+	stats            ProtocolStats
+	decoder          ProtocolDecoder
+
+	// EnableMSSP accepts Mud Server Status Protocol offered by server
+	EnableMSSP bool
+	mssp       map[string][]string
+	// EnableMCCP accepts compression of output (MCCP2) offered by server
+	EnableMCCP bool
+
+	// OnUnsolicited receives lines (e.g. async log messages) received
+	// while no command is in flight. They are collected before each
+	// command during UnsolicitedWait (10ms by default) instead of discarding
+	OnUnsolicited   func(line []byte)
+	UnsolicitedWait time.Duration
+	// DiscardMode defines what is done with data received before
+	// each command, ExecuteDiscarding overrides it for one call
+	DiscardMode     DiscardMode
+	callDiscardMode DiscardMode
+
+	// HistorySize is a size of scrollback buffer of session output
+	// available via History(). Zero disables the buffer
+	HistorySize int
+	history     *ringBuffer
+	tee         []io.Writer
+	teeBuf      []byte
+
+	// EORPrompts enables END-OF-RECORD option and detection of prompts
+	// by IAC EOR and IAC GA markers instead of regular expressions
+	EORPrompts bool
+	eorMark    bool
+	eorPending bool
+
+	// MatchWindow limits the tail of output scanned for prompts
+	// (4KB by default), so matching cost doesn't depend on output size.
+	// Prompts longer than the window are not detected
+	MatchWindow int
+
+	// windowSize is width and height of terminal reported via NAWS
+	windowSize [2]uint16
+
+	// TerminalSpeed is transmit and receive speed reported via
+	// TERMINAL-SPEED option, e.g. "38400,38400". Empty value refuses option
+	TerminalSpeed string
+	// Location is announced via SEND-LOCATION option, e.g. "Building 5, room 12"
+	Location string
+	// XDisplay is X display location reported via X-DISPLAY-LOCATION
+	// option, e.g. "ws12.example.com:0.0"
+	XDisplay string
+	// EnableFlowControl accepts TOGGLE-FLOW-CONTROL option,
+	// state requested by server is available via FlowControl()
+	EnableFlowControl bool
+	flowControl       bool
+	restartAny        bool
+
+	// EnableBinary accepts TRANSMIT-BINARY option in both directions.
+	// CR of sent and received data isn't escaped with NUL, when
+	// option is enabled. Transfer requests option regardless of it
+	EnableBinary bool
+
+	// InitCommands are executed by Dial after login,
+	// e.g. "terminal length 0"
+	InitCommands []string
+
+	// AutoConfirm answers confirmation questions (e.g. "[confirm]", "(y/n)")
+	// interjected in command output during Execute. Response is sent
+	// with CRLF, so empty response just presses Enter
+	AutoConfirm map[*regexp.Regexp]string
+
+	// OnSend receives all data sent to server, including login,
+	// answers and negotiation, after redaction. It must not write to session
+	OnSend func(data []byte)
+	// Redact hides sensitive data in send logs and OnSend.
+	// By default, Password is replaced with asterisks
+	Redact func(data []byte) []byte
+
+	// DryRun makes Dial and commands do nothing but record commands,
+	// which would be sent, available via DryRunCommands(). Commands
+	// return output of DryRunOutput or empty output. Written data
+	// is recorded too, available via DryRunData()
+	DryRun         bool
+	DryRunOutput   func(command string) []byte
+	dryRunData     []byte
+	dryRunCommands []string
+
+	// CommandPolicy is called before command is sent,
+	// denied commands aren't sent and return ErrPolicyDenied
+	CommandPolicy CommandPolicy
+
+	// WriteRate limits sent bytes per second and CommandRate limits
+	// commands per second, e.g. to avoid tripping flood protections
+	// of devices. Zero means unlimited
+	WriteRate      int
+	CommandRate    float64
+	writeLimiter   *tokenBucket
+	commandLimiter *tokenBucket
+
+	// KeepPrompt keeps the matched banner at the end of command output
+	KeepPrompt bool
+
+	// BlockSettle is a pause after prompt, during which ExecuteBlock
+	// waits for more output of remaining lines (200ms by default)
+	BlockSettle time.Duration
+
+	// KeepaliveInterval enables running of KeepaliveCommand (empty line
+	// by default), when session is idle for the interval. Failed command
+	// marks session unhealthy, see Healthy
+	KeepaliveInterval time.Duration
+	KeepaliveCommand  string
+	OnUnhealthy       func(err error)
+	execMu            sync.Mutex
+	users             int
+	lastActive        int64
+	unhealthy         int32
+	keepaliveMu       sync.Mutex
+	keepaliveStop     chan struct{}
+
+	// PromptRules push prompts after commands, which change prompt.
+	// BannerRe is the current prompt of stack, see PushPrompt
+	PromptRules []PromptRule
+	promptStack []*regexp.Regexp
+
+	// Prompts selects defaults of LoginRe, PasswordRe and BannerRe,
+	// which aren't set
+	Prompts PromptVersion
 
 	Delimiter  byte
 	LoginRe    *regexp.Regexp
 	PasswordRe *regexp.Regexp
 	BannerRe   *regexp.Regexp
+	EchoMode   EchoMode
 }
 
 func (tc *TelnetClient) setDefaultParams() {
@@ -66,116 +292,424 @@ func (tc *TelnetClient) setDefaultParams() {
 		tc.Port = "23"
 	}
 	if tc.ReadTimeout == 0 {
-		tc.ReadTimeout = 10 * time.Second
+		tc.ReadTimeout = defaultReadTimeout
 	}
-	if tc.Verbose && tc.LogWriter == nil {
+	if (tc.Verbose || tc.Trace) && tc.LogWriter == nil {
 		tc.LogWriter = bufio.NewWriter(os.Stdout)
 	}
 	if tc.Delimiter == 0 {
 		tc.Delimiter = defaultDelimiter
 	}
+	loginRe, passwordRe, bannerRe := tc.Prompts.defaultPrompts()
 	if tc.LoginRe == nil {
-		tc.LoginRe = defaultLoginRe
+		tc.LoginRe = loginRe
 	}
 	if tc.PasswordRe == nil {
-		tc.PasswordRe = defaultPasswordRe
+		tc.PasswordRe = passwordRe
 	}
 	if tc.BannerRe == nil {
-		tc.BannerRe = defaultBannerRe
+		tc.BannerRe = bannerRe
 	}
 }
 
 func (tc *TelnetClient) log(format string, params ...interface{}) {
 	if tc.Verbose {
-		fmt.Fprintf(tc.LogWriter, "telnet: "+format+"\n", params...)
+		// Tags are passed as parameter, since values may contain '%'
+		params = append([]interface{}{formatTags(tc.Tags)}, params...)
+		fmt.Fprintf(tc.LogWriter, "telnet: %s"+format+"\n", params...)
 		tc.LogWriter.Flush()
 	}
 }
 
-// Dial does open connect to telnet server
-func (tc *TelnetClient) Dial() (err error) {
+// Dial does open connect to telnet server.
+// WithTransport option replaces TCP connection with custom transport
+func (tc *TelnetClient) Dial(opts ...DialOption) (err error) {
 	tc.setDefaultParams()
 
-	tc.log("Trying connect to %s:%s", tc.Address, tc.Port)
-	if tc.ConnTimeout > 0 {
-		tc.conn, err = net.DialTimeout("tcp", tc.Address+":"+tc.Port, tc.ConnTimeout)
-	} else {
-		tc.conn, err = net.Dial("tcp", tc.Address+":"+tc.Port)
+	var options dialOptions
+	for _, opt := range opts {
+		opt(&options)
 	}
-	if err != nil {
+
+	if tc.DryRun {
+		tc.log("Dry run, connection to %s isn't established", tc.Address)
+		tc.setConn(dryRunTransport{})
 		return
 	}
 
-	tc.reader = bufio.NewReader(tc.conn)
-	tc.writer = bufio.NewWriter(tc.conn)
-	err = tc.conn.SetReadDeadline(time.Now().Add(tc.ReadTimeout))
-	if err != nil {
-		return
+	tc.dialTrace = options.trace
+
+	conn := options.transport
+	if conn != nil {
+		tc.log("Using custom transport")
+	} else {
+		conn, err = tc.dialAddresses()
+		if err != nil {
+			return
+		}
 	}
 
+	tc.setConn(conn)
+	tc.setupOptions()
+
+	loginTimeout := tc.ReadTimeout
+	if tc.LoginTimeout > 0 {
+		loginTimeout = tc.LoginTimeout
+	}
+	tc.setReadDeadline(time.Now().Add(loginTimeout))
+
 	tc.log("Waiting for the first banner")
 	err = tc.waitWelcomeSigns()
+	if tc.dialTrace != nil && tc.dialTrace.LoginDone != nil {
+		tc.dialTrace.LoginDone(err)
+	}
+	if err != nil {
+		return
+	}
+
+	for _, command := range tc.InitCommands {
+		_, err = tc.Execute(command)
+		if err != nil {
+			return fmt.Errorf("telnet: init command %q failed: %w", command, err)
+		}
+	}
+
+	if tc.KeepaliveInterval > 0 {
+		tc.startKeepalive()
+	}
+
+	return
+}
+
+// dialAddresses connects to Address or Addresses in order
+// until connection is established
+func (tc *TelnetClient) dialAddresses() (conn net.Conn, err error) {
+	var addresses []string
+
+	for _, address := range append([]string{tc.Address}, tc.Addresses...) {
+		if address == "" {
+			continue
+		}
+		if _, _, e := net.SplitHostPort(address); e != nil {
+			address = net.JoinHostPort(address, tc.Port)
+		}
+		addresses = append(addresses, address)
+	}
+	if len(addresses) == 0 {
+		addresses = append(addresses, net.JoinHostPort("", tc.Port))
+	}
+
+	for _, address := range addresses {
+		tc.log("Trying connect to %s", address)
+		conn, err = tc.dialAddress(address)
+		if err == nil {
+			tc.connectedAddress = address
+			return
+		}
+		tc.log("Failed to connect to %s: %v", address, err)
+	}
+
+	if len(addresses) > 1 {
+		err = fmt.Errorf("telnet: all %d addresses failed, the last one: %w", len(addresses), err)
+	}
 
 	return
 }
 
+// ConnectedAddress returns address, which connection is established to
+func (tc *TelnetClient) ConnectedAddress() string {
+	return tc.connectedAddress
+}
+
+// Close closes connection. It is safe to call Close from another
+// goroutine: pending reads and writes return ErrClosed.
+// Repeated calls do nothing
 func (tc *TelnetClient) Close() {
-	tc.conn.Close()
+	if !atomic.CompareAndSwapInt32(&tc.closed, 0, 1) || tc.conn == nil {
+		return
+	}
+	tc.stopKeepalive()
+	tc.stopCompression()
+	tc.log("Close connection")
+
+	if err := tc.conn.Close(); err != nil {
+		tc.log("Failed to close connection: %v", err)
+	}
+}
+
+// isClosed reports whether Close has been called
+func (tc *TelnetClient) isClosed() bool {
+	return atomic.LoadInt32(&tc.closed) == 1
+}
+
+func (tc *TelnetClient) setConn(conn Transport) {
+	tc.stopCompression()
+	tc.conn = conn
+	atomic.StoreInt32(&tc.closed, 0)
+	atomic.StoreInt32(&tc.unhealthy, 0)
+	tc.stats = ProtocolStats{}
+	tc.decoder = ProtocolDecoder{}
+	tc.afterCR = false
+	tc.reader = bufio.NewReader(&traceReader{tc: tc, r: &deadlineReader{tc: tc, conn: conn}})
+	tc.writer = bufio.NewWriter(conn)
+}
+
+// setReadDeadline sets deadline of the current reading operation
+func (tc *TelnetClient) setReadDeadline(t time.Time) {
+	tc.deadline = t
+}
+
+// readDeadline returns deadline of the next read,
+// inactivity is set when InactivityTimeout comes before deadline
+func (tc *TelnetClient) readDeadline() (deadline time.Time, inactivity bool) {
+	deadline = tc.deadline
+
+	if tc.InactivityTimeout > 0 {
+		d := time.Now().Add(tc.InactivityTimeout)
+		if deadline.IsZero() || d.Before(deadline) {
+			deadline = d
+			inactivity = true
+		}
+	}
+
+	return
+}
+
+// deadlineReader refreshes connection deadline before each read,
+// so both overall and inactivity timeouts are applied.
+// Compressed stream is read without deadline, inflater applies it instead
+type deadlineReader struct {
+	tc   *TelnetClient
+	conn Transport
 }
 
-func (tc *TelnetClient) skipSBSequence() (err error) {
-	var peeked []byte
+func (r *deadlineReader) Read(p []byte) (n int, err error) {
+	tc := r.tc
+	var deadline time.Time
+	inactivity := false
+
+	if !tc.isInflating() {
+		deadline, inactivity = tc.readDeadline()
+	}
+
+	err = r.conn.SetReadDeadline(deadline)
+	if err == nil {
+		n, err = r.conn.Read(p)
+	}
+	if err != nil && tc.isClosed() {
+		err = ErrClosed
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() && inactivity {
+		err = ErrStalled
+	}
+
+	return
+}
+
+// handleEvent performs command found by protocol decoder
+func (tc *TelnetClient) handleEvent(event *Event) (err error) {
+	// Only commands inside of subnegotiation are invalid known commands
+	// other than SB, they aren't counted as separate commands
+	if !event.Invalid || event.Command < CmdEOF || event.Command == SB {
+		tc.stats.Commands++
+	}
+	if event.Invalid {
+		tc.stats.UnexpectedBytes++
+		tc.trace("recv invalid IAC %s", event.Command)
+		return
+	}
+
+	switch event.Command {
+	case WILL, WONT, DO, DONT:
+		tc.traceCommand("recv", event.Command, event.Option)
+		err = tc.handleNegotiation(event.Command, event.Option)
+	case SB:
+		tc.handleSubnegotiation(event.Option, event.Data)
+	default:
+		tc.trace("recv IAC %s", event.Command)
+		if tc.EORPrompts && (event.Command == CmdEOR || event.Command == CmdGA) {
+			tc.eorMark = true
+		}
+	}
+
+	return
+}
 
+// ReadByte receives byte from remote server, avoiding commands.
+// If filters are set, byte is taken from filtered data
+func (tc *TelnetClient) ReadByte() (b byte, err error) {
+	defer tc.use()()
+
+	return tc.nextByte()
+}
+
+// nextByte receives byte like ReadByte, session isn't marked as used
+func (tc *TelnetClient) nextByte() (b byte, err error) {
 	for {
-		_, err = tc.reader.Discard(1)
-		if err != nil {
+		b, err = tc.readByte()
+		if err != errRecordEnd {
 			return
 		}
+	}
+}
+
+// readByte receives byte like ReadByte, but it returns errRecordEnd,
+// when prompt is marked by IAC EOR or IAC GA
+func (tc *TelnetClient) readByte() (b byte, err error) {
+	if len(tc.pushback) > 0 {
+		b, tc.pushback = tc.pushback[0], tc.pushback[1:]
+		return
+	}
+
+	b, err = tc.readFilteredByte()
+	if err == nil {
+		tc.recordHistory(b)
+		tc.teeByte(b)
+	}
+
+	return
+}
+
+// readFilteredByte receives byte of data, which is passed through filters
+func (tc *TelnetClient) readFilteredByte() (b byte, err error) {
+	if len(tc.Filters) == 0 {
+		return tc.readDataByte()
+	}
+
+	for len(tc.filtered) == 0 {
+		if tc.eorPending {
+			tc.eorPending = false
+			return 0, errRecordEnd
+		}
 
-		peeked, err = tc.reader.Peek(2)
+		err = tc.fillFiltered()
 		if err != nil {
 			return
 		}
+	}
+
+	b, tc.filtered = tc.filtered[0], tc.filtered[1:]
 
-		if peeked[0] == IAC && peeked[1] == SE {
-			_, err = tc.reader.Discard(2)
+	return
+}
+
+// fillFiltered reads all received data and runs it through filters
+func (tc *TelnetClient) fillFiltered() (err error) {
+	var b byte
+
+	chunk := make([]byte, 0, tc.reader.Buffered()+1)
+	for {
+		b, err = tc.readDataByte()
+		if err == errRecordEnd {
+			tc.eorPending = true
+			err = nil
 			break
 		}
+		if err != nil {
+			break
+		}
+
+		chunk = append(chunk, b)
+		if !tc.pendingData() {
+			break
+		}
+	}
+
+	tc.filtered = tc.applyFilters(chunk)
+	if len(tc.filtered) > 0 {
+		// error is returned by the next reading
+		err = nil
 	}
 
 	return
 }
 
-func (tc *TelnetClient) skipCommand() (err error) {
-	var peeked []byte
+// buffered returns number of bytes, which can be read without blocking.
+// Received bytes are counted, only if there is data among them
+func (tc *TelnetClient) buffered() int {
+	n := len(tc.pushback) + len(tc.filtered)
+	if tc.pendingData() {
+		n += tc.reader.Buffered()
+	}
 
-	peeked, err = tc.reader.Peek(1)
-	if err != nil {
-		return
+	return n
+}
+
+// pendingData reports whether received but not decoded bytes
+// contain data, so reading of them doesn't block on telnet commands
+func (tc *TelnetClient) pendingData() bool {
+	n := tc.reader.Buffered()
+	if n == 0 {
+		return false
 	}
 
-	switch peeked[0] {
-	case WILL, WONT, DO, DONT:
-		_, err = tc.reader.Discard(2)
-	case SB:
-		err = tc.skipSBSequence()
+	peeked, _ := tc.reader.Peek(n)
+	if tc.decoder.state == decodeData && Command(peeked[0]) != IAC {
+		return true
+	}
+
+	return tc.decoder.hasData(peeked)
+}
+
+// Buffered returns number of received bytes, which can be read
+// without blocking. Telnet commands, which aren't parsed yet,
+// are counted too, so it is an upper bound of available data
+func (tc *TelnetClient) Buffered() int {
+	return tc.buffered()
+}
+
+// Peek returns the next n bytes of data without consuming them.
+// It blocks until n bytes are received or read deadline is reached,
+// in the latter case fewer bytes are returned with error
+func (tc *TelnetClient) Peek(n int) (data []byte, err error) {
+	defer tc.use()()
+
+	var b byte
+	for len(data) < n {
+		b, err = tc.readByte()
+		if err != nil {
+			break
+		}
+		data = append(data, b)
 	}
+	tc.unread(data)
 
 	return
 }
 
-// ReadByte receives byte from remote server, avoiding commands
-func (tc *TelnetClient) ReadByte() (b byte, err error) {
+// readDataByte receives byte from remote server, avoiding commands
+func (tc *TelnetClient) readDataByte() (b byte, err error) {
+	var isData bool
+	var event *Event
+
 	for {
 		b, err = tc.reader.ReadByte()
-		if err != nil || b != IAC {
+		if err != nil {
 			break
 		}
 
-		err = tc.skipCommand()
+		// Decoder keeps state of incomplete sequence, so IAC received
+		// as the last byte before timeout isn't lost
+		isData, event = tc.decoder.DecodeByte(b)
+		if isData {
+			if tc.skipCRNul(b) {
+				continue
+			}
+			break
+		}
+		if event == nil {
+			continue
+		}
+
+		err = tc.handleEvent(event)
 		if err != nil {
 			break
 		}
+
+		if tc.eorMark {
+			tc.eorMark = false
+			return 0, errRecordEnd
+		}
 	}
 
 	return
@@ -184,13 +718,20 @@ func (tc *TelnetClient) ReadByte() (b byte, err error) {
 // ReadUntil reads bytes until a specific symbol.
 // Delimiter character will be written to result buffer
 func (tc *TelnetClient) ReadUntil(data *[]byte, delim byte) (n int, err error) {
+	defer tc.use()()
+
+	return tc.readUntil(data, delim)
+}
+
+// readUntil reads bytes like ReadUntil, session isn't marked as used
+func (tc *TelnetClient) readUntil(data *[]byte, delim byte) (n int, err error) {
 	var b byte
 
 	for {
 		if b == delim {
 			return
 		}
-		b, err = tc.ReadByte()
+		b, err = tc.nextByte()
 		if err != nil {
 			break
 		}
@@ -220,16 +761,19 @@ func findNewLinePos(data []byte) int {
 // ReadUntilPrompt reads data until process function stops.
 // If process function returns true, reading will be stopped
 // Process function give chunk of line i.e. from start of line
-// to last white space or whole line, if next line delimiter is found
+// to last white space or whole line, if next line delimiter is found.
+// Chunk is limited by MatchWindow bytes before the delimiter
 func (tc *TelnetClient) ReadUntilPrompt(
 	process func(data []byte) bool,
 ) (output []byte, err error) {
+	defer tc.use()()
+
 	var n int
 	var delimPos int
 	var linePos int
 	var chunk []byte
 
-	output = make([]byte, 0, 64*1024)
+	output = make([]byte, 0, initialOutputSize)
 
 	for {
 		// Usually, if system print a prompt,
@@ -237,18 +781,27 @@ func (tc *TelnetClient) ReadUntilPrompt(
 		// prompt has ':' or whitespace in end of line.
 		// However, may be cases which have another behaviors.
 		// So client may freeze
-		n, err = tc.ReadUntil(&output, tc.Delimiter)
+		n, err = tc.readUntil(&output, tc.Delimiter)
 		if err != nil {
 			return
 		}
 
+		// New line is searched only in the new data.
+		// CR may be the last byte of previous data
+		from := delimPos - 1
+		if from < 0 {
+			from = 0
+		}
 		delimPos += n
-		n = findNewLinePos(output)
+		n = findNewLinePos(output[from:])
 		if n != -1 {
-			linePos = n + 2
+			linePos = from + n + 2
 		}
 
 		chunk = output[linePos:delimPos]
+		if window := tc.matchWindow(); len(chunk) > window {
+			chunk = output[delimPos-window : delimPos]
+		}
 
 		if process(chunk) {
 			break
@@ -258,83 +811,402 @@ func (tc *TelnetClient) ReadUntilPrompt(
 	return
 }
 
-// ReadUntilBanner reads until banner, i.e. whole output from command
+// ReadUntilRegexp reads data until regular expression matches.
+// Only received data and MatchWindow bytes of the stream tail are scanned,
+// so output size doesn't affect matching performance. Returns whole output
+// up to the end of match, and the match itself.
+// Data received after the match is left for the next reading
+func (tc *TelnetClient) ReadUntilRegexp(
+	re *regexp.Regexp,
+) (output []byte, matched []byte, err error) {
+	defer tc.use()()
+
+	return tc.readUntilRegexp(re)
+}
+
+// readUntilRegexp reads data like ReadUntilRegexp,
+// session isn't marked as used
+func (tc *TelnetClient) readUntilRegexp(
+	re *regexp.Regexp,
+) (output []byte, matched []byte, err error) {
+	output, loc, err := tc.readUntilMatch(re.FindIndex)
+	if loc != nil {
+		matched = output[loc[0]:loc[1]]
+	}
+
+	return
+}
+
+// readUntilMatch reads data until find function returns location
+// of the match in the scanned region. Location is relative to output.
+// Region is scanned, when all received data is read,
+// it consists of new data and MatchWindow bytes before it.
+// If EORPrompts is set, reading also stops at the end of record
+// and the last line is considered as a prompt
+func (tc *TelnetClient) readUntilMatch(
+	find func(region []byte) []int,
+) (output []byte, loc []int, err error) {
+	var b byte
+	var checked int
+
+	output = make([]byte, 0, initialOutputSize)
+
+	for {
+		b, err = tc.readByte()
+		if err == errRecordEnd {
+			start := len(output) - tc.matchWindow()
+			if start < 0 {
+				start = 0
+			}
+			start += bytes.LastIndexByte(output[start:], '\n') + 1
+			err = nil
+			loc = find(output[start:])
+			if loc == nil {
+				loc = []int{0, len(output) - start}
+			}
+			loc[0], loc[1] = start+loc[0], start+loc[1]
+			return
+		}
+		if err != nil {
+			return
+		}
+		output = append(output, b)
+
+		if tc.buffered() > 0 {
+			continue
+		}
+
+		start := checked - tc.matchWindow()
+		if start < 0 {
+			start = 0
+		}
+		checked = len(output)
+
+		loc = find(output[start:])
+		if loc != nil {
+			loc[0], loc[1] = start+loc[0], start+loc[1]
+			tc.unread(output[loc[1]:])
+			output = output[:loc[1]]
+			return
+		}
+	}
+}
+
+// matchWindow returns MatchWindow or its default value
+func (tc *TelnetClient) matchWindow() int {
+	if tc.MatchWindow > 0 {
+		return tc.MatchWindow
+	}
+
+	return defaultMatchWindow
+}
+
+// unread returns data to the stream, so it is read again
+// by the next reading
+func (tc *TelnetClient) unread(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	tc.pushback = append(append([]byte{}, data...), tc.pushback...)
+}
+
+// ReadUntilBanner reads until banner, i.e. whole output from command.
+// The banner is removed from the end of output, unless KeepPrompt is set
 func (tc *TelnetClient) ReadUntilBanner() (output []byte, err error) {
-	output, err = tc.ReadUntilPrompt(func(data []byte) bool {
-		m := tc.BannerRe.Find(data)
-		return len(m) > 0
-	})
+	defer tc.use()()
+
+	var matched []byte
 
-	output = tc.BannerRe.ReplaceAll(output, []byte{})
-	output = bytes.Trim(output, " ")
+	output, matched, err = tc.readUntilRegexp(tc.BannerRe)
+	output = tc.trimBanner(output, matched)
 
 	return
 }
 
-func (tc *TelnetClient) findInputPrompt(
-	re *regexp.Regexp,
-	response string,
-	buffer []byte,
-) bool {
-	match := re.Find(buffer)
-	if len(match) == 0 {
-		return false
+// trimBanner removes the trailing banner from command output.
+// Text matching banner in the middle of output is kept
+func (tc *TelnetClient) trimBanner(output []byte, matched []byte) []byte {
+	if tc.KeepPrompt {
+		return output
+	}
+
+	if len(matched) > 0 {
+		if n := bytes.LastIndex(output, matched); n != -1 {
+			output = output[:n]
+		}
 	}
 
-	tc.Write([]byte(response + "\r\n"))
+	return bytes.Trim(output, " ")
+}
 
-	return true
+// findFirst returns index of regular expression, which matches
+// earlier than others, and location of the match
+func findFirst(data []byte, res ...*regexp.Regexp) (idx int, loc []int) {
+	idx = -1
+
+	for i, re := range res {
+		m := re.FindIndex(data)
+		if m != nil && (loc == nil || m[0] < loc[0]) {
+			idx, loc = i, m
+		}
+	}
+
+	return
+}
+
+// findLast returns index of regexp, which match starts last in data,
+// and location of the match
+func findLast(data []byte, res ...*regexp.Regexp) (idx int, loc []int) {
+	idx = -1
+
+	for i, re := range res {
+		all := re.FindAllIndex(data, -1)
+		if len(all) == 0 {
+			continue
+		}
+		if m := all[len(all)-1]; loc == nil || m[0] > loc[0] {
+			idx, loc = i, m
+		}
+	}
+
+	return
 }
 
 // waitWelcomeSigns waits for appearance of the first banner
-// If detect login prompt, it will authorize
+// If detect login prompt, it will authorize.
+// Only the last prompt of received data is answered, as prompts
+// before it are already abandoned by server (e.g. TACACS printing
+// "Username:" again after timeout or failure)
 func (tc *TelnetClient) waitWelcomeSigns() (err error) {
-	_, err = tc.ReadUntilPrompt(func(data []byte) bool {
-		if tc.findInputPrompt(tc.LoginRe, tc.Login, data) {
-			tc.log("Found login prompt")
-			return false
+	var found int
+	var data []byte
+	var output []byte
+	var prompted bool
+	var attempts int
+	var loginSent bool
+
+	lockoutRe := tc.LockoutRe
+	if lockoutRe == nil {
+		lockoutRe = defaultLockoutRe
+	}
+
+	for {
+		data, _, err = tc.readUntilMatch(func(window []byte) (loc []int) {
+			// Lockout is reported only in answer to credentials,
+			// the same words in motd before them are just a warning
+			if attempts == 0 {
+				found, loc = findLast(window, tc.LoginRe, tc.PasswordRe, tc.BannerRe)
+				return
+			}
+			found, loc = findLast(window, tc.LoginRe, tc.PasswordRe, tc.BannerRe, lockoutRe)
+			return
+		})
+		output = append(output, data...)
+		if err != nil {
+			if isTimeout(err) {
+				err = ErrLoginTimeout
+			}
+			return &LoginError{Output: output, Err: err, Tags: tc.Tags}
 		}
-		if tc.findInputPrompt(tc.PasswordRe, tc.Password, data) {
-			tc.log("Found password prompt")
-			return false
+		if !prompted && tc.dialTrace != nil && tc.dialTrace.NegotiationDone != nil {
+			tc.dialTrace.NegotiationDone()
 		}
+		prompted = true
 
-		m := tc.BannerRe.Find(data)
-		return len(m) > 0
-	})
+		// Password prompt following login belongs to the same attempt
+		if found == 0 || found == 1 && !loginSent {
+			attempts++
+			if tc.MaxLoginAttempts > 0 && attempts > tc.MaxLoginAttempts {
+				tc.log("Login attempts are exceeded")
+				return &LoginError{Output: output, Err: ErrLoginAttempts, Tags: tc.Tags}
+			}
+		}
+		loginSent = found == 0
 
-	return
+		switch found {
+		case 0:
+			tc.log("Found login prompt")
+			_, err = tc.write([]byte(tc.Login + "\r\n"))
+		case 1:
+			tc.log("Found password prompt")
+			_, err = tc.write([]byte(tc.Password + "\r\n"))
+		case 3:
+			tc.log("Found lockout message")
+			return &LoginError{Output: output, Err: ErrLockedOut, Tags: tc.Tags}
+		default:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
 }
 
 // Write sends raw data to remove telnet server
 func (tc *TelnetClient) Write(data []byte) (n int, err error) {
-	n, err = tc.writer.Write(data)
+	defer tc.use()()
+
+	return tc.write(data)
+}
+
+// write sends raw data like Write, session isn't marked as used
+func (tc *TelnetClient) write(data []byte) (n int, err error) {
+	if tc.DryRun {
+		tc.recordDryRunData(data)
+		return len(data), nil
+	}
+
+	tc.writeMu.Lock()
+	defer tc.writeMu.Unlock()
+
+	if tc.isClosed() {
+		return 0, ErrClosed
+	}
+
+	// Password is hidden in trace like in send log
+	if tc.Trace {
+		redacted := tc.redact(data)
+		tc.traceDump("send", redacted)
+		tc.traceCommands("send", redacted)
+	}
+
+	// Data exceeding WriteRate burst is sent by chunks
+	for n < len(data) {
+		var m int
+		size := tc.limitWrite(len(data) - n)
+		m, err = tc.writer.Write(data[n : n+size])
+		n += m
+		if err == nil {
+			err = tc.writer.Flush()
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err != nil && tc.isClosed() {
+		err = ErrClosed
+	}
 	if err == nil {
-		err = tc.writer.Flush()
+		tc.logSend(data)
 	}
 
 	return
 }
 
-// Execute sends command on remote server and returns whole output
-func (tc *TelnetClient) Execute(
-	name string,
-	args ...string,
-) (stdout []byte, err error) {
-	_, err = tc.reader.Discard(tc.reader.Buffered())
-	if err != nil {
-		return
+// Drain reads unsolicited output (syslog messages, link notifications)
+// until remote server is quiet for timeout, so it isn't attributed
+// to the next command
+func (tc *TelnetClient) Drain(timeout time.Duration) (output []byte, err error) {
+	defer tc.use()()
+
+	return tc.drain(timeout, time.Time{})
+}
+
+// drain reads output like Drain, session isn't marked as used.
+// Non-zero limit stops reading of server, which is never quiet
+func (tc *TelnetClient) drain(timeout time.Duration, limit time.Time) (output []byte, err error) {
+	var b byte
+
+	for {
+		if tc.buffered() == 0 {
+			deadline := time.Now().Add(timeout)
+			if !limit.IsZero() && limit.Before(deadline) {
+				deadline = limit
+			}
+			tc.setReadDeadline(deadline)
+		}
+
+		b, err = tc.nextByte()
+		if isTimeout(err) {
+			err = nil
+			break
+		}
+		if err != nil {
+			break
+		}
+		output = append(output, b)
 	}
 
-	request := []byte(name + " " + strings.Join(args, " ") + "\r\n")
-	tc.log("Send command: %s", request[:len(request)-2])
-	tc.Write(request)
+	if len(output) > 0 {
+		tc.log("Drained %d bytes of unsolicited output", len(output))
+	}
 
-	stdout, err = tc.ReadUntilBanner()
-	if err != nil {
-		return
+	return
+}
+
+// isTimeout reports whether error is caused by read deadline
+func isTimeout(err error) bool {
+	if err == ErrStalled {
+		return true
+	}
+
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
+
+// discardBuffered drops received but not read data.
+// If virtual channel is active, data is kept in its pending buffer.
+// Otherwise data is handled according to DiscardMode
+func (tc *TelnetClient) discardBuffered() (err error) {
+	if tc.activeChannel != nil {
+		return tc.stashBuffered()
 	}
-	tc.log("Received data with size = %d", len(stdout))
+
+	switch tc.discardMode() {
+	case DiscardAuto, DiscardToUnsolicited:
+		if tc.OnUnsolicited != nil {
+			return tc.routeUnsolicited()
+		}
+	case DiscardToHistory:
+		return tc.keepHistory()
+	}
+
+	tc.pushback = nil
+	tc.filtered = nil
+	err = tc.discardReceived()
+
+	return
+}
+
+// discardReceived drops data of received bytes. Telnet commands
+// among them are still performed, so negotiation isn't broken
+func (tc *TelnetClient) discardReceived() (err error) {
+	var b byte
+	var isData bool
+	var event *Event
+
+	for tc.reader.Buffered() > 0 {
+		b, err = tc.reader.ReadByte()
+		if err != nil {
+			return
+		}
+
+		isData, event = tc.decoder.DecodeByte(b)
+		if isData {
+			tc.skipCRNul(b)
+		}
+		if event != nil {
+			err = tc.handleEvent(event)
+			if err != nil {
+				return
+			}
+		}
+	}
+	tc.eorMark = false
+
+	return
+}
+
+// Execute sends command on remote server and returns whole output.
+// If output can't be read completely, output received so far
+// is returned along with *ExecuteError
+func (tc *TelnetClient) Execute(
+	name string,
+	args ...string,
+) (stdout []byte, err error) {
+	result, err := tc.ExecuteResult(name, args...)
+	stdout = result.Output
 
 	return
 }
@@ -3,7 +3,9 @@ package telnet
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"io"
+	"net"
 	"regexp"
 	"sync"
 	"testing"
@@ -77,36 +79,32 @@ func (rc *testReadCase) run(
 	wg.Wait()
 }
 
-func Test_skipSBSequence(t *testing.T) {
+func Test_TelnetClient_readCommands(t *testing.T) {
 	tc := &TelnetClient{ReadTimeout: 10 * time.Millisecond}
 	tests := []testReadCase{
 		{
-			name: "skipSBSequence: Just skip SB/SE sequence",
+			name: "readCommands: Just skip SB/SE sequence",
 			args: [][]byte{
-				{0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, 0xf0},
+				{0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, 0xf0},
 			},
 			want: []byte{},
 		},
 		{
-			name: "skipSBSequence: Skip first SB/SE sequence",
+			name: "readCommands: Skip SB/SE sequences around text",
 			args: [][]byte{
 				{
-					0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, 0xf0,
+					0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, 0xf0,
 					0x70, 0x6c, 0x61, 0x69, 0x6e, 0x20, 0x74, 0x65, 0x78, 0x74,
 					0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, 0xf0,
 				},
 			},
-			want: []byte{
-				0x70, 0x6c, 0x61, 0x69, 0x6e, 0x20, 0x74, 0x65, 0x78, 0x74,
-				0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, 0xf0,
-			},
+			want: []byte{0x70, 0x6c, 0x61, 0x69, 0x6e, 0x20, 0x74, 0x65, 0x78, 0x74},
 		},
 		{
-			name:        "skipSBSequence error: without SE",
-			wantTimeout: true,
+			name: "readCommands error: SB without SE doesn't hang",
 			args: [][]byte{
 				{
-					0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, // 0xf0,
+					0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, // 0xf0,
 					0x70, 0x6c, 0x61, 0x69, 0x6e, 0x20, 0x74, 0x65, 0x78, 0x74,
 					0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, // 0xff, 0xf0,
 				},
@@ -114,10 +112,10 @@ func Test_skipSBSequence(t *testing.T) {
 			want: []byte{},
 		},
 		{
-			name: "skipSBSequence error: without IAC for SE",
+			name: "readCommands error: without IAC for SE",
 			args: [][]byte{
 				{
-					0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xf0,
+					0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xf0,
 					0x70, 0x6c, 0x61, 0x69, 0x6e, 0x20, 0x74, 0x65, 0x78, 0x74,
 					0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, 0xf0,
 					0x70, 0x6c, 0x61, 0x69, 0x6e, 0x20, 0x74, 0x65, 0x78, 0x74,
@@ -126,72 +124,56 @@ func Test_skipSBSequence(t *testing.T) {
 			want: []byte{0x70, 0x6c, 0x61, 0x69, 0x6e, 0x20, 0x74, 0x65, 0x78, 0x74},
 		},
 		{
-			name: "skipSBSequence error: multiple IAC",
+			name: "readCommands: IAC IAC before SB is data",
 			args: [][]byte{
-				{0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, 0xf0},
+				{0xff, 0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, 0xf0},
 			},
-			want: []byte{},
+			want: []byte{0xff, 0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tt.run(t, tc, func() []byte {
-				_ = tc.skipSBSequence()
-				buf := make([]byte, tc.reader.Buffered())
-				tc.reader.Read(buf)
-
-				return buf
-			})
-		})
-	}
-}
-
-func Test_skipCommand(t *testing.T) {
-	tc := &TelnetClient{ReadTimeout: 10 * time.Millisecond}
-	tests := []testReadCase{
 		{
-			name: "skipCommand: Just skip SB/SE sequence",
+			name: "readCommands: Just skip DO command",
 			args: [][]byte{
-				{0xfa, 0x18, 0, 0x56, 0x54, 0x32, 0x32, 0x30, 0xff, 0xf0},
+				{0xff, 0xfd, 0x03},
 			},
 			want: []byte{},
 		},
 		{
-			name: "skipCommand: Just skip DO command",
+			name: "readCommands: Skip DO commands",
 			args: [][]byte{
-				{0xfd, 0x03},
+				{0xff, 0xfd, 0x03, 0xff, 0xfd, 0x21},
 			},
 			want: []byte{},
 		},
 		{
-			name: "skipCommand: Skip first DO command",
+			name: "readCommands: IAC IAC before DO is data",
 			args: [][]byte{
-				{0xfd, 0x03, 0xff, 0xfd, 0x21},
+				{0xff, 0xff, 0xfd, 0x03, 0xff, 0xfd, 0x21},
 			},
-			want: []byte{0xff, 0xfd, 0x21},
+			want: []byte{0xff, 0xfd, 0x03},
 		},
 		{
-			name: "skipCommand: Nothing, invalid format",
-			args: [][]byte{
-				{0xff, 0xfd, 0x03, 0xff, 0xfd, 0x21},
-			},
-			want: []byte{0xff, 0xfd, 0x03, 0xff, 0xfd, 0x21},
-		},
-		{
-			name: "skipCommand: Plain text",
+			name: "readCommands: Plain text",
 			args: [][]byte{
 				{0x70, 0x6c, 0x61, 0x69, 0x6e},
 			},
 			want: []byte{0x70, 0x6c, 0x61, 0x69, 0x6e},
 		},
 	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.run(t, tc, func() []byte {
-				tc.skipCommand()
-				buf := make([]byte, tc.reader.Buffered())
-				tc.reader.Read(buf)
+				tc.decoder = ProtocolDecoder{}
+				tc.reader.Peek(1)
+
+				buf := []byte{}
+				for tc.buffered() > 0 {
+					b, err := tc.ReadByte()
+					if err != nil {
+						break
+					}
+					buf = append(buf, b)
+				}
 
 				return buf
 			})
@@ -318,6 +300,49 @@ func Test_TelnetClient_ReadUntilPrompt(t *testing.T) {
 	}
 }
 
+func Test_TelnetClient_ReadUntilRegexp(t *testing.T) {
+	tc := &TelnetClient{
+		ReadTimeout: 10 * time.Millisecond,
+		Delimiter:   defaultDelimiter,
+	}
+	re := regexp.MustCompile("R1(\\(config\\))?#")
+	tests := []testReadCase{
+		{
+			name: "ReadUntilRegexp: prompt in the end",
+			args: [][]byte{
+				[]byte("Building configuration...\r\n"),
+				[]byte("R1(config)#"),
+			},
+			want: []byte("R1(config)#"),
+		},
+		{
+			name: "ReadUntilRegexp: first match",
+			args: [][]byte{
+				[]byte("[OK]\r\nR1# R1(config)# "),
+			},
+			want: []byte("R1#"),
+		},
+		{
+			name:        "ReadUntilRegexp: timeout",
+			wantTimeout: true,
+			args: [][]byte{
+				[]byte("R2# "),
+			},
+			want: []byte{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, tc, func() []byte {
+				// data after the previous match is dropped with the reader
+				tc.pushback = nil
+				_, matched, _ := tc.ReadUntilRegexp(re)
+				return matched
+			})
+		})
+	}
+}
+
 func Test_TelnetClient_ReadUntilBanner(t *testing.T) {
 	tc := &TelnetClient{
 		ReadTimeout: 10 * time.Millisecond,
@@ -350,6 +375,42 @@ func Test_TelnetClient_ReadUntilBanner(t *testing.T) {
 	}
 }
 
+func Test_TelnetClient_trimBanner(t *testing.T) {
+	output := "hostname admin@R1:~$ is set\r\nadmin@R1:~$ "
+	matched := "admin@R1:~$"
+
+	tests := []struct {
+		name       string
+		keepPrompt bool
+		want       string
+	}{
+		{
+			name: "trimBanner: only trailing banner is removed",
+			want: "hostname admin@R1:~$ is set\r\n",
+		},
+		{
+			name:       "trimBanner: KeepPrompt",
+			keepPrompt: true,
+			want:       output,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := &TelnetClient{
+				BannerRe:   defaultBannerRe,
+				KeepPrompt: tt.keepPrompt,
+			}
+
+			fact := tc.trimBanner([]byte(output), []byte(matched))
+			if string(fact) != tt.want {
+				t.Errorf(
+					"[%s] wrong result:\n\t\tfact = %q\n\t\twant = %q",
+					tt.name, fact, tt.want)
+			}
+		})
+	}
+}
+
 func Test_TelnetClient_waitWelcomeSigns(t *testing.T) {
 	tc := &TelnetClient{
 		ReadTimeout: 10 * time.Millisecond,
@@ -426,3 +487,586 @@ func Test_TelnetClient_waitWelcomeSigns(t *testing.T) {
 		}
 	})
 }
+
+func Test_TelnetClient_InactivityTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		payload func(w io.Writer)
+		want    []byte
+		wantErr error
+	}{
+		{
+			name:    "InactivityTimeout: stalled device",
+			timeout: 20 * time.Millisecond,
+			payload: func(w io.Writer) {
+				w.Write([]byte("Building configuration...\r\n"))
+			},
+			want:    []byte("Building configuration...\r\n"),
+			wantErr: ErrStalled,
+		},
+		{
+			name:    "InactivityTimeout: slow output",
+			timeout: 50 * time.Millisecond,
+			payload: func(w io.Writer) {
+				for _, line := range []string{"one\r\n", "two\r\n", "R1# "} {
+					time.Sleep(10 * time.Millisecond)
+					w.Write([]byte(line))
+				}
+			},
+			want: []byte("one\r\ntwo\r\nR1#"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			tc := &TelnetClient{
+				Delimiter:         defaultDelimiter,
+				InactivityTimeout: tt.timeout,
+			}
+			tc.setConn(client)
+			tc.setReadDeadline(time.Now().Add(time.Second))
+
+			go tt.payload(server)
+
+			output, _, err := tc.ReadUntilRegexp(regexp.MustCompile("R1#"))
+			if err != tt.wantErr {
+				t.Errorf("[%s] unexpected error: %v", tt.name, err)
+			}
+			if bytes.Compare(output, tt.want) != 0 {
+				t.Errorf(
+					"[%s] wrong output:\n\t\tfact = %q\n\t\twant = %q",
+					tt.name, output, tt.want)
+			}
+		})
+	}
+}
+
+func Test_TelnetClient_Execute_partialOutput(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout:       time.Second,
+		InactivityTimeout: 20 * time.Millisecond,
+		Delimiter:         defaultDelimiter,
+		BannerRe:          defaultBannerRe,
+	}
+	tc.setConn(client)
+
+	// server
+	go func() {
+		command := make([]byte, 64)
+		server.Read(command)
+		server.Write([]byte("PING 8.8.8.8 (8.8.8.8): 56 data bytes\r\n"))
+	}()
+
+	stdout, err := tc.Execute("ping", "8.8.8.8")
+
+	var execErr *ExecuteError
+	if !errors.As(err, &execErr) || !errors.Is(err, ErrStalled) {
+		t.Fatalf("Execute: unexpected error: %v", err)
+	}
+
+	want := []byte("PING 8.8.8.8 (8.8.8.8): 56 data bytes\r\n")
+	if bytes.Compare(stdout, want) != 0 || bytes.Compare(execErr.Output, want) != 0 {
+		t.Errorf(
+			"Execute: wrong partial output:\n\t\tfact = %q\n\t\twant = %q",
+			stdout, want)
+	}
+	if execErr.Command != "ping 8.8.8.8" {
+		t.Errorf("Execute: wrong command in error: %q", execErr.Command)
+	}
+}
+
+func Test_TelnetClient_EORPrompts(t *testing.T) {
+	tc := &TelnetClient{
+		ReadTimeout: 10 * time.Millisecond,
+		Delimiter:   defaultDelimiter,
+		BannerRe:    defaultBannerRe,
+		EORPrompts:  true,
+	}
+	tests := []testReadCase{
+		{
+			name: "EORPrompts: prompt marked by EOR",
+			args: [][]byte{
+				[]byte("You see a troll.\r\n"),
+				[]byte("HP:100 MV:80> "),
+				{0xff, 0xef},
+			},
+			want: []byte("You see a troll.\r\n"),
+		},
+		{
+			name: "EORPrompts: prompt marked by GA",
+			args: [][]byte{
+				{'o', 'k', '\r', '\n', '>', 0xff, 0xf9},
+			},
+			want: []byte("ok\r\n"),
+		},
+		{
+			name:        "EORPrompts: without marker",
+			wantTimeout: true,
+			args: [][]byte{
+				[]byte("HP:100 MV:80> "),
+			},
+			want: []byte{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, tc, func() []byte {
+				buf, _ := tc.ReadUntilBanner()
+				return buf
+			})
+		})
+	}
+}
+
+func Test_TelnetClient_Drain(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{}
+	tc.setConn(client)
+
+	// server
+	go func() {
+		server.Write([]byte("%LINK-3-UPDOWN: Interface Gi0/1, changed state to down\r\n"))
+		time.Sleep(10 * time.Millisecond)
+		server.Write([]byte("%LINEPROTO-5-UPDOWN: Line protocol on Interface Gi0/1\r\n"))
+	}()
+
+	output, err := tc.Drain(50 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Drain: unexpected error: %v", err)
+	}
+
+	want := []byte(
+		"%LINK-3-UPDOWN: Interface Gi0/1, changed state to down\r\n" +
+			"%LINEPROTO-5-UPDOWN: Line protocol on Interface Gi0/1\r\n")
+	if bytes.Compare(output, want) != 0 {
+		t.Errorf(
+			"Drain: wrong output:\n\t\tfact = %q\n\t\twant = %q",
+			output, want)
+	}
+}
+
+func Test_TelnetClient_Peek(t *testing.T) {
+	tc := &TelnetClient{
+		reader: bufio.NewReader(bytes.NewReader([]byte(
+			"R1#\xff\xfb\x01show clock\r\n"))),
+	}
+
+	data, err := tc.Peek(6)
+	if err != nil || string(data) != "R1#sho" {
+		t.Fatalf("Peek: unexpected result: %q, %v", data, err)
+	}
+	if n := tc.Buffered(); n != 15 {
+		t.Errorf("Peek: wrong number of buffered bytes: %d", n)
+	}
+
+	var output []byte
+	_, err = tc.ReadUntil(&output, '\n')
+	if err != nil || string(output) != "R1#show clock\r\n" {
+		t.Errorf("Peek: peeked data isn't read again: %q, %v", output, err)
+	}
+
+	data, err = tc.Peek(1)
+	if err == nil || len(data) != 0 {
+		t.Errorf("Peek: unexpected result at the end of data: %q, %v", data, err)
+	}
+}
+
+func Test_TelnetClient_IACSplit(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{ReadTimeout: time.Second}
+	tc.setConn(client)
+
+	// server
+	resume := make(chan bool)
+	go func() {
+		server.Write([]byte("show\xff"))
+		<-resume
+		server.Write([]byte("\xfb\x01 \xff\xff R1#"))
+	}()
+
+	// IAC is the last byte received before timeout
+	output, err := tc.Drain(20 * time.Millisecond)
+	if err != nil || string(output) != "show" {
+		t.Fatalf("IACSplit: unexpected result of Drain: %q, %v", output, err)
+	}
+	close(resume)
+
+	tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+	output, _, err = tc.ReadUntilRegexp(regexp.MustCompile("R1#"))
+	if err != nil {
+		t.Fatalf("IACSplit: unexpected error: %v", err)
+	}
+	if want := " \xff R1#"; string(output) != want {
+		t.Errorf("IACSplit: wrong output:\n\t\tfact = %q\n\t\twant = %q", output, want)
+	}
+	if stats := tc.Stats(); stats.Commands != 1 || stats.RefusedOptions != 1 {
+		t.Errorf("IACSplit: split command isn't decoded: %+v", stats)
+	}
+}
+
+func Test_TelnetClient_trailingCommand(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{ReadTimeout: time.Second}
+	tc.setConn(client)
+
+	// server
+	go server.Write([]byte("R1#\xff\xf1"))
+
+	start := time.Now()
+	tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+	output, _, err := tc.ReadUntilRegexp(regexp.MustCompile("R1#"))
+	if err != nil || string(output) != "R1#" {
+		t.Fatalf("trailingCommand: unexpected result: %q, %v", output, err)
+	}
+	if elapsed := time.Since(start); elapsed > tc.ReadTimeout/2 {
+		t.Errorf("trailingCommand: prompt followed by command is matched after %v", elapsed)
+	}
+}
+
+func Test_TelnetClient_MatchWindow(t *testing.T) {
+	long := bytes.Repeat([]byte("x"), 64*1024)
+	data := append(append([]byte{}, long...), " R1#"...)
+
+	tests := []struct {
+		name   string
+		window int
+		want   int
+	}{
+		{name: "MatchWindow: default", want: defaultMatchWindow},
+		{name: "MatchWindow: custom", window: 128, want: 128},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := &TelnetClient{
+				Delimiter:   defaultDelimiter,
+				MatchWindow: tt.window,
+			}
+
+			var longest int
+			tc.reader = bufio.NewReader(bytes.NewReader(data))
+			output, err := tc.ReadUntilPrompt(func(chunk []byte) bool {
+				if len(chunk) > longest {
+					longest = len(chunk)
+				}
+				return bytes.HasSuffix(chunk, []byte("R1# "))
+			})
+			if err != io.EOF || len(output) != len(data) {
+				t.Errorf("[%s] ReadUntilPrompt: unexpected result: %d bytes, %v",
+					tt.name, len(output), err)
+			}
+			if longest != tt.want {
+				t.Errorf("[%s] ReadUntilPrompt: chunk of %d bytes, want %d",
+					tt.name, longest, tt.want)
+			}
+
+			tc.reader = bufio.NewReader(bytes.NewReader(data))
+			output, matched, err := tc.ReadUntilRegexp(regexp.MustCompile("R1#"))
+			if err != nil || string(matched) != "R1#" || len(output) != len(data) {
+				t.Errorf("[%s] ReadUntilRegexp: unexpected result: %d bytes, %q, %v",
+					tt.name, len(output), matched, err)
+			}
+		})
+	}
+}
+
+func Test_TelnetClient_LoginTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout:  10 * time.Second,
+		LoginTimeout: 50 * time.Millisecond,
+	}
+
+	// server
+	go func() {
+		server.Write([]byte("Booting kernel...\r\n"))
+	}()
+
+	start := time.Now()
+	err := tc.Dial(WithTransport(client))
+	defer tc.Close()
+
+	var loginErr *LoginError
+	if !errors.As(err, &loginErr) || !errors.Is(err, ErrLoginTimeout) {
+		t.Fatalf("LoginTimeout: unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("LoginTimeout: Dial took %v", elapsed)
+	}
+	if want := []byte("Booting kernel...\r\n"); bytes.Compare(loginErr.Output, want) != 0 {
+		t.Errorf(
+			"LoginTimeout: wrong output:\n\t\tfact = %q\n\t\twant = %q",
+			loginErr.Output, want)
+	}
+}
+
+// loginServer writes the first chunk and each next one after reading
+// answer of client. It returns all received data, when conn is closed
+func loginServer(conn net.Conn, chunks []string) <-chan string {
+	received := make(chan string, 1)
+
+	go func() {
+		var answers []byte
+		buf := make([]byte, 64)
+
+		for i, chunk := range chunks {
+			if i > 0 {
+				n, err := conn.Read(buf)
+				if err != nil {
+					break
+				}
+				answers = append(answers, buf[:n]...)
+			}
+			if _, err := conn.Write([]byte(chunk)); err != nil {
+				break
+			}
+		}
+		received <- string(answers)
+	}()
+
+	return received
+}
+
+func Test_TelnetClient_MaxLoginAttempts(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		chunks      []string
+		wantErr     error
+		wantSent    string
+	}{
+		{
+			name:        "attempts are exceeded",
+			maxAttempts: 1,
+			chunks:      []string{"R1 login: ", "Password: ", "\r\nLogin incorrect\r\nR1 login: "},
+			wantErr:     ErrLoginAttempts,
+			wantSent:    "admin\r\nsecret\r\n",
+		},
+		{
+			name:        "password only",
+			maxAttempts: 2,
+			chunks:      []string{"Password: ", "\r\n% Bad passwords\r\nPassword: ", "\r\nR1#"},
+			wantSent:    "secret\r\nsecret\r\n",
+		},
+		{
+			name:     "account is locked",
+			chunks:   []string{"R1 login: ", "Password: ", "\r\n%Account locked\r\n"},
+			wantErr:  ErrLockedOut,
+			wantSent: "admin\r\nsecret\r\n",
+		},
+		{
+			name: "lockout warning in motd",
+			chunks: []string{
+				"Accounts are locked out after 3 failed attempts\r\nR1 login: ",
+				"Password: ",
+				"\r\nR1#",
+			},
+			wantSent: "admin\r\nsecret\r\n",
+		},
+		{
+			name: "prompt after lockout message",
+			chunks: []string{
+				"R1 login: ",
+				"Password: ",
+				"\r\nLogin incorrect, account is locked for 1 minute\r\nR1 login: ",
+				"Password: ",
+				"\r\nR1#",
+			},
+			wantSent: "admin\r\nsecret\r\nadmin\r\nsecret\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer server.Close()
+
+			tc := &TelnetClient{
+				ReadTimeout:      time.Second,
+				Login:            "admin",
+				Password:         "secret",
+				MaxLoginAttempts: tt.maxAttempts,
+				LoginRe:          regexp.MustCompile("R1 login:"),
+				PasswordRe:       defaultPasswordRe,
+				BannerRe:         regexp.MustCompile("R1#"),
+			}
+			tc.setConn(client)
+			tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+			received := loginServer(server, tt.chunks)
+
+			err := tc.waitWelcomeSigns()
+			client.Close()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("[%s] MaxLoginAttempts: unexpected error: %v", tt.name, err)
+			}
+			if sent := <-received; sent != tt.wantSent {
+				t.Errorf("[%s] MaxLoginAttempts: wrong sent data:\n\t\tfact = %q\n\t\twant = %q",
+					tt.name, sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func Test_TelnetClient_TACACSLogin(t *testing.T) {
+	const motd = "\r\n\r\n" +
+		"**********************************************************************\r\n" +
+		"* Authorized access only. Disconnect IMMEDIATELY if you are not an   *\r\n" +
+		"* authorized user! All activity is logged.                           *\r\n" +
+		"**********************************************************************\r\n" +
+		"\r\nUser Access Verification\r\n\r\n"
+
+	tests := []struct {
+		name     string
+		chunks   []string
+		wantSent string
+	}{
+		{
+			name: "retry after failure",
+			chunks: []string{
+				motd + "Username: ",
+				"admin\r\nPassword: ",
+				"\r\n% Authentication failed\r\n" + motd + "Username: ",
+				"admin\r\nPassword: ",
+				"\r\n\r\nR1#",
+			},
+			wantSent: "admin\r\nsecret\r\nadmin\r\nsecret\r\n",
+		},
+		{
+			name: "prompt repeated after server timeout",
+			chunks: []string{
+				motd + "Username: \r\n" +
+					"% TACACS+ server 10.0.0.5/49 timed out, using local database\r\n" +
+					"\r\nUsername: ",
+				"admin\r\nPassword: ",
+				"\r\n\r\nR1#",
+			},
+			wantSent: "admin\r\nsecret\r\n",
+		},
+		{
+			name: "password prompt of previous attempt",
+			chunks: []string{
+				motd + "Username: ",
+				"admin\r\nPassword: \r\n% Login invalid\r\n\r\nUsername: ",
+				"admin\r\nPassword: ",
+				"\r\nR1#",
+			},
+			wantSent: "admin\r\nadmin\r\nsecret\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer server.Close()
+
+			tc := &TelnetClient{
+				ReadTimeout: time.Second,
+				Login:       "admin",
+				Password:    "secret",
+				LoginRe:     regexp.MustCompile("Username:"),
+				PasswordRe:  defaultPasswordRe,
+				BannerRe:    regexp.MustCompile("R1#"),
+			}
+			tc.setConn(client)
+			tc.setReadDeadline(time.Now().Add(tc.ReadTimeout))
+			received := loginServer(server, tt.chunks)
+
+			err := tc.waitWelcomeSigns()
+			client.Close()
+			if err != nil {
+				t.Errorf("[%s] TACACSLogin: unexpected error: %v", tt.name, err)
+			}
+			if sent := <-received; sent != tt.wantSent {
+				t.Errorf("[%s] TACACSLogin: wrong sent data:\n\t\tfact = %q\n\t\twant = %q",
+					tt.name, sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func Test_TelnetClient_Addresses(t *testing.T) {
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Addresses: can't listen: %v", err)
+	}
+	deadAddress := dead.Addr().String()
+	dead.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Addresses: can't listen: %v", err)
+	}
+	defer ln.Close()
+
+	// server
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("admin@R1:~$ "))
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	tc := &TelnetClient{
+		Address:     deadAddress,
+		Addresses:   []string{host},
+		Port:        port,
+		ReadTimeout: time.Second,
+	}
+	if err = tc.Dial(); err != nil {
+		t.Fatalf("Addresses: unexpected error: %v", err)
+	}
+	defer tc.Close()
+
+	if tc.ConnectedAddress() != ln.Addr().String() {
+		t.Errorf("Addresses: wrong connected address %q", tc.ConnectedAddress())
+	}
+}
+
+func Test_TelnetClient_Close(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	tc := &TelnetClient{Delimiter: defaultDelimiter}
+	tc.setConn(client)
+	tc.setReadDeadline(time.Now().Add(time.Second))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := tc.ReadUntilPrompt(func(data []byte) bool {
+			return false
+		})
+		done <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	tc.Close()
+	if err := <-done; err != ErrClosed {
+		t.Errorf("Close: pending read returned %v", err)
+	}
+	// repeated call does nothing
+	tc.Close()
+	if _, err := tc.Write([]byte("ls\r\n")); err != ErrClosed {
+		t.Errorf("Close: write returned %v", err)
+	}
+}
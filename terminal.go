@@ -0,0 +1,7 @@
+package telnet
+
+import "errors"
+
+// ErrNotTerminal is returned by terminal helpers, when file descriptor
+// isn't a terminal or terminals aren't supported on the platform
+var ErrNotTerminal = errors.New("telnet: not a terminal")
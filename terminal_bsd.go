@@ -0,0 +1,11 @@
+//go:build darwin || freebsd
+// +build darwin freebsd
+
+package telnet
+
+import "syscall"
+
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
@@ -0,0 +1,32 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package telnet
+
+import "os"
+
+// TerminalState keeps terminal settings to restore them after raw mode
+type TerminalState struct{}
+
+// IsTerminal reports whether file descriptor is a terminal
+func IsTerminal(fd int) bool {
+	return false
+}
+
+// MakeRaw isn't supported on the platform
+func MakeRaw(fd int) (*TerminalState, error) {
+	return nil, ErrNotTerminal
+}
+
+// RestoreTerminal isn't supported on the platform
+func RestoreTerminal(fd int, state *TerminalState) error {
+	return ErrNotTerminal
+}
+
+// TerminalSize isn't supported on the platform
+func TerminalSize(fd int) (width, height int, err error) {
+	return 0, 0, ErrNotTerminal
+}
+
+// notifyResize does nothing, as resize signal isn't supported
+func notifyResize(ch chan<- os.Signal) {}
@@ -0,0 +1,79 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package telnet
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// TerminalState keeps terminal settings to restore them after raw mode
+type TerminalState struct {
+	termios syscall.Termios
+}
+
+func ioctl(fd int, request uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// IsTerminal reports whether file descriptor is a terminal
+func IsTerminal(fd int) bool {
+	var termios syscall.Termios
+	return ioctl(fd, ioctlGetTermios, unsafe.Pointer(&termios)) == nil
+}
+
+// MakeRaw puts terminal into raw mode, so keys are passed to server
+// as they are pressed and local echo is disabled.
+// Returned state restores previous mode with RestoreTerminal
+func MakeRaw(fd int) (*TerminalState, error) {
+	var state TerminalState
+	if err := ioctl(fd, ioctlGetTermios, unsafe.Pointer(&state.termios)); err != nil {
+		return nil, ErrNotTerminal
+	}
+
+	raw := state.termios
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+
+	if err := ioctl(fd, ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// RestoreTerminal restores terminal mode saved by MakeRaw
+func RestoreTerminal(fd int, state *TerminalState) error {
+	return ioctl(fd, ioctlSetTermios, unsafe.Pointer(&state.termios))
+}
+
+// TerminalSize returns width and height of terminal
+func TerminalSize(fd int) (width, height int, err error) {
+	var ws struct {
+		row, col, xpixel, ypixel uint16
+	}
+	if err = ioctl(fd, syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, ErrNotTerminal
+	}
+
+	return int(ws.col), int(ws.row), nil
+}
+
+// notifyResize relays terminal size changes (SIGWINCH) to channel
+func notifyResize(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGWINCH)
+}
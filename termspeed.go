@@ -0,0 +1,49 @@
+package telnet
+
+// TOGGLE-FLOW-CONTROL commands
+const (
+	lflowOff        = 0
+	lflowOn         = 1
+	lflowRestartAny = 2
+	lflowRestartXON = 3
+)
+
+// sendTerminalSpeed answers TERMINAL-SPEED SEND request with
+// IAC SB TERMINAL-SPEED IS speed IAC SE
+func (tc *TelnetClient) sendTerminalSpeed(data []byte) {
+	if len(data) == 0 || data[0] != sbSEND {
+		return
+	}
+
+	tc.log("Send terminal speed %s", tc.TerminalSpeed)
+	err := tc.sendSubnegotiation(OptTerminalSpeed, append([]byte{sbIS}, tc.TerminalSpeed...))
+	if err != nil {
+		tc.log("Failed to send terminal speed: %v", err)
+	}
+}
+
+// toggleFlowControl records flow control state requested by server
+func (tc *TelnetClient) toggleFlowControl(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	switch data[0] {
+	case lflowOff:
+		tc.flowControl = false
+	case lflowOn:
+		tc.flowControl = true
+	case lflowRestartAny:
+		tc.restartAny = true
+	case lflowRestartXON:
+		tc.restartAny = false
+	}
+	tc.log("Flow control: enabled %v, restart by any key %v", tc.flowControl, tc.restartAny)
+}
+
+// FlowControl reports whether server requested local flow control
+// (XON/XOFF) and whether any key or only XON restarts output.
+// EnableFlowControl has to be set before Dial
+func (tc *TelnetClient) FlowControl() (enabled bool, restartAny bool) {
+	return tc.flowControl, tc.restartAny
+}
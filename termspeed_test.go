@@ -0,0 +1,42 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func Test_TelnetClient_TerminalSpeed(t *testing.T) {
+	sent := &bytes.Buffer{}
+	tc := &TelnetClient{
+		TerminalSpeed:     "38400,38400",
+		EnableFlowControl: true,
+		writer:            bufio.NewWriter(sent),
+	}
+	tc.reader = bufio.NewReader(bytes.NewReader([]byte{
+		0xff, 0xfd, 0x20,
+		0xff, 0xfa, 0x20, 0x01, 0xff, 0xf0,
+		0xff, 0xfd, 0x21,
+		0xff, 0xfa, 0x21, 0x00, 0xff, 0xf0,
+		0xff, 0xfa, 0x21, 0x02, 0xff, 0xf0,
+		'$',
+	}))
+	tc.setupOptions()
+
+	b, err := tc.ReadByte()
+	if err != nil || b != '$' {
+		t.Fatalf("TerminalSpeed: unexpected result of ReadByte: %v, %v", b, err)
+	}
+
+	want := append([]byte{0xff, 0xfb, 0x20, 0xff, 0xfa, 0x20, 0x00}, "38400,38400"...)
+	want = append(want, 0xff, 0xf0, 0xff, 0xfb, 0x21)
+	if bytes.Compare(sent.Bytes(), want) != 0 {
+		t.Errorf("TerminalSpeed: wrong answers:\n\t\tfact = % x\n\t\twant = % x",
+			sent.Bytes(), want)
+	}
+
+	enabled, restartAny := tc.FlowControl()
+	if enabled || !restartAny {
+		t.Errorf("TerminalSpeed: wrong flow control state: %v, %v", enabled, restartAny)
+	}
+}
@@ -0,0 +1,75 @@
+package telnet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func (tc *TelnetClient) trace(format string, params ...interface{}) {
+	if tc.Trace {
+		params = append([]interface{}{formatTags(tc.Tags)}, params...)
+		fmt.Fprintf(tc.LogWriter, "telnet: %s"+format+"\n", params...)
+		tc.LogWriter.Flush()
+	}
+}
+
+// traceDump writes hexdump of raw protocol bytes
+func (tc *TelnetClient) traceDump(direction string, data []byte) {
+	if !tc.Trace || len(data) == 0 {
+		return
+	}
+
+	tc.trace(
+		"%s %d bytes\n%s",
+		direction, len(data), strings.TrimRight(hex.Dump(data), "\n"))
+}
+
+// traceCommands writes decoded names of commands found in data
+func (tc *TelnetClient) traceCommands(direction string, data []byte) {
+	if !tc.Trace {
+		return
+	}
+
+	for i := 0; i < len(data)-1; i++ {
+		if Command(data[i]) != IAC {
+			continue
+		}
+
+		cmd := Command(data[i+1])
+		switch cmd {
+		case IAC:
+			i++
+		case WILL, WONT, DO, DONT, SB:
+			if i+2 < len(data) {
+				tc.traceCommand(direction, cmd, Option(data[i+2]))
+				i += 2
+			}
+		default:
+			tc.trace("%s IAC %s", direction, cmd)
+			i++
+		}
+	}
+}
+
+func (tc *TelnetClient) traceCommand(direction string, cmd Command, opt Option) {
+	tc.trace("%s IAC %s %s", direction, cmd, opt)
+}
+
+// traceReader dumps raw bytes received from connection
+type traceReader struct {
+	tc *TelnetClient
+	r  io.Reader
+}
+
+func (r *traceReader) Read(p []byte) (n int, err error) {
+	n, err = r.r.Read(p)
+	// Compressed stream is read in background,
+	// inflater dumps decompressed data instead
+	if !r.tc.isInflating() {
+		r.tc.traceDump("recv", p[:n])
+	}
+
+	return
+}
@@ -0,0 +1,69 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_TelnetClient_Trace(t *testing.T) {
+	logs := &bytes.Buffer{}
+	sent := &bytes.Buffer{}
+
+	tc := &TelnetClient{
+		Trace:     true,
+		LogWriter: bufio.NewWriter(logs),
+		writer:    bufio.NewWriter(sent),
+	}
+	tc.reader = bufio.NewReader(&traceReader{
+		tc: tc,
+		r: bytes.NewReader([]byte{
+			0xff, 0xfd, 0x1f,
+			0xff, 0xfa, 0x18, 0x01, 0xff, 0xf0,
+			'$',
+		}),
+	})
+
+	b, err := tc.ReadByte()
+	if err != nil || b != '$' {
+		t.Fatalf("Trace: unexpected result of ReadByte: %v, %v", b, err)
+	}
+	tc.Write([]byte{0xff, 0xfc, 0x1f, 0xff, 0xfb, 0x18})
+
+	for _, want := range []string{
+		"telnet: recv 10 bytes\n00000000  ff fd 1f ff fa 18 01 ff  f0 24",
+		"telnet: recv IAC DO NAWS\n",
+		"telnet: recv IAC SB TTYPE [01] IAC SE\n",
+		"telnet: send 6 bytes\n00000000  ff fc 1f ff fb 18",
+		"telnet: send IAC WONT NAWS\n",
+		"telnet: send IAC WILL TTYPE\n",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("Trace: log doesn't contain %q:\n%s", want, logs.String())
+		}
+	}
+}
+
+func Test_TelnetClient_TraceRedact(t *testing.T) {
+	logs := &bytes.Buffer{}
+	sent := &bytes.Buffer{}
+
+	tc := &TelnetClient{
+		Password:  "s3cr3t",
+		Trace:     true,
+		LogWriter: bufio.NewWriter(logs),
+		writer:    bufio.NewWriter(sent),
+	}
+	tc.Write([]byte("s3cr3t\r\n"))
+
+	if sent.String() != "s3cr3t\r\n" {
+		t.Errorf("Trace redact: wrong sent data: %q", sent.String())
+	}
+	if strings.Contains(logs.String(), "s3cr3t") {
+		t.Errorf("Trace redact: password is in log:\n%s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "telnet: send 8 bytes\n") {
+		t.Errorf("Trace redact: send is not traced:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,408 @@
+package telnet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// TransferProtocol is a framing used to pump file through the session
+type TransferProtocol int
+
+const (
+	// XMODEM uses 128 bytes blocks and checksum or CRC
+	// depending on receiver request
+	XMODEM TransferProtocol = iota
+	// XMODEM1K uses 1024 bytes blocks with CRC
+	XMODEM1K
+	// YMODEM is XMODEM1K with file name and size in the header block
+	YMODEM
+)
+
+// XMODEM control characters
+const (
+	xmSOH = 0x01
+	xmSTX = 0x02
+	xmEOT = 0x04
+	xmACK = 0x06
+	xmNAK = 0x15
+	xmCAN = 0x18
+	xmCRC = 'C'
+	xmSUB = 0x1a
+)
+
+var (
+	// ErrTransferCanceled is returned when receiver cancels the transfer
+	ErrTransferCanceled = errors.New("telnet: transfer is canceled by receiver")
+	// ErrTransferTimeout is returned when receiver doesn't answer in time
+	ErrTransferTimeout = errors.New("telnet: transfer receiver is not responding")
+	// ErrTransferRetries is returned when block was rejected too many times
+	ErrTransferRetries = errors.New("telnet: transfer retries are exhausted")
+)
+
+// TransferOptions describes in-band file transfer
+type TransferOptions struct {
+	Protocol TransferProtocol
+	// FileName and FileSize are sent in YMODEM header block
+	FileName string
+	FileSize int64
+	// Timeout is a time to wait each answer of receiver, 10 seconds by default
+	Timeout time.Duration
+	// Retries is a number of attempts to resend rejected block, 10 by default
+	Retries int
+	// NoBinary keeps NVT mode during transfer, every CR of blocks is
+	// sent as CR NUL. By default TRANSMIT-BINARY option is requested
+	// in both directions, blocks are sent as is, if server agrees
+	NoBinary bool
+}
+
+func (opts *TransferOptions) setDefaultParams() {
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Retries == 0 {
+		opts.Retries = 10
+	}
+}
+
+// Transfer sends command (e.g. "copy xmodem: flash:"), which starts
+// receiving on remote side, and pumps data from r with XMODEM/YMODEM framing.
+// Empty command means receiver is already started.
+// Telnet mode is restored when transfer is finished
+func (tc *TelnetClient) Transfer(
+	command string,
+	r io.Reader,
+	opts TransferOptions,
+) (err error) {
+	defer tc.use()()
+
+	opts.setDefaultParams()
+
+	if command != "" {
+		tc.log("Start transfer: %s", command)
+		_, err = tc.write([]byte(command + "\r\n"))
+		if err != nil {
+			return
+		}
+	}
+
+	if !opts.NoBinary {
+		var restore func()
+		restore, err = tc.requestBinary()
+		defer restore()
+		if err != nil {
+			return
+		}
+	}
+
+	s := &xmodemSender{tc: tc, opts: opts}
+	err = s.send(r)
+	if err != nil {
+		tc.write([]byte{xmCAN, xmCAN})
+		return
+	}
+	tc.log("Transfer is finished, sent %d bytes", s.sent)
+
+	return
+}
+
+// requestBinary requests TRANSMIT-BINARY option in directions,
+// where it isn't enabled yet. Returned function restores NVT mode of them
+func (tc *TelnetClient) requestBinary() (restore func(), err error) {
+	local := !tc.LocalEnabled(OptBinary)
+	remote := !tc.RemoteEnabled(OptBinary)
+	restore = func() {
+		if local {
+			tc.disableLocal(OptBinary)
+		}
+		if remote {
+			tc.disableRemote(OptBinary)
+		}
+	}
+
+	if local {
+		err = tc.requestLocal(OptBinary, nil)
+		if err != nil {
+			return
+		}
+	}
+	if remote {
+		err = tc.requestRemote(OptBinary, nil)
+	}
+
+	return
+}
+
+type xmodemSender struct {
+	tc   *TelnetClient
+	opts TransferOptions
+	crc  bool
+	sent int64
+}
+
+func (s *xmodemSender) send(r io.Reader) (err error) {
+	err = s.waitStart()
+	if err != nil {
+		return
+	}
+
+	if s.opts.Protocol == YMODEM {
+		err = s.sendBlock(0, s.header(), 128)
+		if err != nil {
+			return
+		}
+		err = s.waitStart()
+		if err != nil {
+			return
+		}
+	}
+
+	size := 128
+	if s.opts.Protocol != XMODEM {
+		size = 1024
+	}
+
+	data := make([]byte, size)
+	for num := byte(1); ; num++ {
+		var n int
+
+		n, err = io.ReadFull(r, data)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return
+		}
+
+		err = s.sendBlock(num, data[:n], size)
+		if err != nil {
+			return
+		}
+		s.sent += int64(n)
+	}
+
+	err = s.sendEOT()
+	if err != nil || s.opts.Protocol != YMODEM {
+		return
+	}
+
+	// Empty header block finishes YMODEM batch
+	err = s.waitStart()
+	if err != nil {
+		return
+	}
+
+	return s.sendBlock(0, nil, 128)
+}
+
+func (s *xmodemSender) header() []byte {
+	header := []byte(s.opts.FileName)
+	header = append(header, 0)
+	header = append(header, strconv.FormatInt(s.opts.FileSize, 10)...)
+
+	return header
+}
+
+// waitStart waits for receiver request, which defines checksum type.
+// Request is a bare byte, so 'C' of text printed before the transfer
+// (command echo, "Copying...", "CCITT") isn't taken for it
+func (s *xmodemSender) waitStart() (err error) {
+	var b, prev byte
+
+	for {
+		// receiver repeats request after a pause,
+		// so bytes received before it aren't its neighbours
+		if s.tc.buffered() == 0 {
+			prev = 0
+		}
+
+		b, err = s.readControl()
+		if err != nil {
+			return
+		}
+		inText := isPrintable(prev) || isPrintable(s.peekControl())
+		prev = b
+
+		switch {
+		case b == xmCAN:
+			return ErrTransferCanceled
+		case inText:
+		case b == xmCRC:
+			s.crc = true
+			return
+		case b == xmNAK:
+			s.crc = false
+			return
+		}
+	}
+}
+
+// peekControl returns the next received byte without waiting for it,
+// zero is returned if nothing is received yet
+func (s *xmodemSender) peekControl() byte {
+	if s.tc.buffered() == 0 {
+		return 0
+	}
+
+	b, err := s.tc.nextByte()
+	if err != nil {
+		return 0
+	}
+	s.tc.unread([]byte{b})
+
+	return b
+}
+
+// isPrintable reports whether b is printable ASCII character
+func isPrintable(b byte) bool {
+	return b >= 0x20 && b < 0x7f
+}
+
+func (s *xmodemSender) sendBlock(num byte, data []byte, size int) (err error) {
+	block := s.frame(num, data, size)
+
+	for attempt := 0; attempt <= s.opts.Retries; attempt++ {
+		data := escapeIAC(block)
+		if !s.tc.LocalEnabled(OptBinary) {
+			data = escapeAllCR(data)
+		}
+		_, err = s.tc.write(data)
+		if err != nil {
+			return
+		}
+
+		err = s.waitAck()
+		if err != ErrTransferRetries {
+			return
+		}
+	}
+
+	return ErrTransferRetries
+}
+
+func (s *xmodemSender) sendEOT() (err error) {
+	for attempt := 0; attempt <= s.opts.Retries; attempt++ {
+		_, err = s.tc.write([]byte{xmEOT})
+		if err != nil {
+			return
+		}
+
+		err = s.waitAck()
+		if err != ErrTransferRetries {
+			return
+		}
+	}
+
+	return ErrTransferRetries
+}
+
+// waitAck returns ErrTransferRetries if block should be resent
+func (s *xmodemSender) waitAck() (err error) {
+	var b byte
+
+	for {
+		b, err = s.readControl()
+		if err == ErrTransferTimeout {
+			return ErrTransferRetries
+		}
+		if err != nil {
+			return
+		}
+
+		switch b {
+		case xmACK:
+			return nil
+		case xmNAK:
+			return ErrTransferRetries
+		case xmCAN:
+			return ErrTransferCanceled
+		}
+	}
+}
+
+func (s *xmodemSender) readControl() (b byte, err error) {
+	s.tc.setReadDeadline(time.Now().Add(s.opts.Timeout))
+
+	b, err = s.tc.nextByte()
+	if isTimeout(err) {
+		err = ErrTransferTimeout
+	}
+
+	return
+}
+
+func (s *xmodemSender) frame(num byte, data []byte, size int) []byte {
+	block := make([]byte, 0, size+5)
+
+	if size == 1024 {
+		block = append(block, xmSTX)
+	} else {
+		block = append(block, xmSOH)
+	}
+	block = append(block, num, ^num)
+
+	payload := make([]byte, size)
+	copy(payload, data)
+	if num != 0 {
+		copy(payload[len(data):], bytes.Repeat([]byte{xmSUB}, size-len(data)))
+	}
+	block = append(block, payload...)
+
+	if s.crc {
+		crc := crc16(payload)
+		block = append(block, byte(crc>>8), byte(crc))
+	} else {
+		block = append(block, checksum(payload))
+	}
+
+	return block
+}
+
+// crc16 is CRC-16/XMODEM
+func crc16(data []byte) (crc uint16) {
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return
+}
+
+func checksum(data []byte) (sum byte) {
+	for _, b := range data {
+		sum += b
+	}
+
+	return
+}
+
+// escapeIAC doubles IAC bytes, so data is not interpreted as commands
+func escapeIAC(data []byte) []byte {
+	if bytes.IndexByte(data, byte(IAC)) == -1 {
+		return data
+	}
+
+	return bytes.Replace(data, []byte{byte(IAC)}, []byte{byte(IAC), byte(IAC)}, -1)
+}
+
+func (s TransferProtocol) String() string {
+	switch s {
+	case XMODEM:
+		return "XMODEM"
+	case XMODEM1K:
+		return "XMODEM-1K"
+	case YMODEM:
+		return "YMODEM"
+	}
+
+	return fmt.Sprintf("TransferProtocol(%d)", int(s))
+}
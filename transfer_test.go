@@ -0,0 +1,210 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+// xmodemReceiver is a minimal CRC receiver of XMODEM/YMODEM blocks.
+// It returns received payloads, including YMODEM header blocks.
+// If binary isn't accepted, NUL following CR is dropped as in NVT mode
+func xmodemReceiver(r *bufio.Reader, w io.Writer, ymodem, negotiate, accept bool) (blocks [][]byte) {
+	afterCR := false
+	readByte := func() byte {
+		for {
+			b, _ := r.ReadByte()
+			if Command(b) == IAC {
+				b, _ = r.ReadByte()
+			}
+			if b == 0 && afterCR && !accept {
+				afterCR = false
+				continue
+			}
+			afterCR = b == '\r'
+			return b
+		}
+	}
+
+	// skip command
+	r.ReadBytes('\n')
+	if negotiate {
+		// IAC WILL BINARY IAC DO BINARY
+		io.ReadFull(r, make([]byte, 6))
+		if accept {
+			w.Write([]byte{0xff, 0xfd, 0x00, 0xff, 0xfb, 0x00})
+		} else {
+			w.Write([]byte{0xff, 0xfe, 0x00, 0xff, 0xfc, 0x00})
+		}
+	}
+	w.Write([]byte{xmCRC})
+
+	for {
+		size := 128
+		switch readByte() {
+		case xmEOT:
+			w.Write([]byte{xmACK})
+			if !ymodem {
+				return
+			}
+			w.Write([]byte{xmCRC})
+			continue
+		case xmSTX:
+			size = 1024
+		}
+
+		num, inv := readByte(), readByte()
+		payload := make([]byte, size)
+		for i := range payload {
+			payload[i] = readByte()
+		}
+		crc := uint16(readByte())<<8 | uint16(readByte())
+
+		if num != ^inv || crc != crc16(payload) {
+			w.Write([]byte{xmNAK})
+			continue
+		}
+		w.Write([]byte{xmACK})
+
+		blocks = append(blocks, payload)
+		if ymodem && num == 0 {
+			if payload[0] == 0 {
+				return
+			}
+			w.Write([]byte{xmCRC})
+		}
+	}
+}
+
+func Test_TelnetClient_Transfer(t *testing.T) {
+	data := bytes.Repeat([]byte{0x00, 0xff, 0x10, 0x7f, '\r', '\n', '\r', 0x00}, 150)
+
+	tests := []struct {
+		name       string
+		opts       TransferOptions
+		refuse     bool
+		wantBlocks int
+	}{
+		{
+			name:       "Transfer: binary is refused",
+			opts:       TransferOptions{Protocol: XMODEM},
+			refuse:     true,
+			wantBlocks: 10,
+		},
+		{
+			name:       "Transfer: NVT mode",
+			opts:       TransferOptions{Protocol: XMODEM1K, NoBinary: true},
+			wantBlocks: 2,
+		},
+		{
+			name:       "Transfer: XMODEM",
+			opts:       TransferOptions{Protocol: XMODEM},
+			wantBlocks: 10,
+		},
+		{
+			name:       "Transfer: XMODEM-1K",
+			opts:       TransferOptions{Protocol: XMODEM1K},
+			wantBlocks: 2,
+		},
+		{
+			name: "Transfer: YMODEM",
+			opts: TransferOptions{
+				Protocol: YMODEM,
+				FileName: "image.bin",
+				FileSize: int64(len(data)),
+			},
+			wantBlocks: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, sw := io.Pipe()
+			cr, cw := io.Pipe()
+
+			tc := &TelnetClient{
+				reader: bufio.NewReader(cr),
+				writer: bufio.NewWriter(sw),
+			}
+
+			blocksCh := make(chan [][]byte, 1)
+			ymodem, negotiate, accept := tt.opts.Protocol == YMODEM, !tt.opts.NoBinary, !tt.refuse
+			go func() {
+				r := bufio.NewReader(sr)
+				blocksCh <- xmodemReceiver(r, cw, ymodem, negotiate, accept && negotiate)
+				// restoring of NVT mode
+				io.Copy(ioutil.Discard, r)
+			}()
+
+			err := tc.Transfer("copy xmodem: flash:", bytes.NewReader(data), tt.opts)
+			sw.Close()
+			if err != nil {
+				t.Fatalf("[%s] unexpected error: %v", tt.name, err)
+			}
+			if tc.LocalEnabled(OptBinary) || tc.RemoteEnabled(OptBinary) {
+				t.Errorf("[%s] binary mode isn't restored", tt.name)
+			}
+
+			blocks := <-blocksCh
+			if len(blocks) != tt.wantBlocks {
+				t.Fatalf(
+					"[%s] wrong number of blocks: fact = %d, want = %d",
+					tt.name, len(blocks), tt.wantBlocks)
+			}
+
+			if tt.opts.Protocol == YMODEM {
+				header := (&xmodemSender{opts: tt.opts}).header()
+				if !bytes.HasPrefix(blocks[0], header) {
+					t.Errorf("[%s] wrong header block: %q", tt.name, blocks[0])
+				}
+				blocks = blocks[1 : len(blocks)-1]
+			}
+
+			received := bytes.TrimRight(bytes.Join(blocks, nil), "\x1a")
+			if bytes.Compare(received, data) != 0 {
+				t.Errorf("[%s] received data differs from sent one", tt.name)
+			}
+		})
+	}
+}
+
+func Test_xmodemSender_waitStart(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		wantCRC bool
+		wantErr error
+	}{
+		{name: "waitStart: CRC request", output: "C", wantCRC: true},
+		{name: "waitStart: checksum request", output: "\x15"},
+		{name: "waitStart: echo before request", output: "copy xmodem: flash:\r\nCopying (CCITT CRC)\r\nC", wantCRC: true},
+		{name: "waitStart: text before checksum request", output: "CCITT is not supported\r\n\x15"},
+		{name: "waitStart: only text", output: "Copying...\r\n", wantErr: ErrTransferTimeout},
+		{name: "waitStart: canceled", output: "Copy\x18", wantErr: ErrTransferCanceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer server.Close()
+			go server.Write([]byte(tt.output))
+
+			tc := &TelnetClient{}
+			tc.setConn(client)
+			defer tc.Close()
+			s := &xmodemSender{tc: tc, opts: TransferOptions{Timeout: 50 * time.Millisecond}}
+
+			err := s.waitStart()
+			if err != tt.wantErr {
+				t.Fatalf("[%s] unexpected error: %v", tt.name, err)
+			}
+			if err == nil && s.crc != tt.wantCRC {
+				t.Errorf("[%s] wrong checksum type: crc = %v", tt.name, s.crc)
+			}
+		})
+	}
+}
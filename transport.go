@@ -0,0 +1,130 @@
+package telnet
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Transport is a connection, which carries telnet data stream.
+// net.Conn satisfies it, so TCP and Unix sockets or net.Pipe can be
+// used directly. Streams without deadlines (e.g. serial ports)
+// can be adapted with StreamTransport
+type Transport interface {
+	io.ReadWriteCloser
+	SetReadDeadline(t time.Time) error
+}
+
+// DialOption configures Dial
+type DialOption func(options *dialOptions)
+
+type dialOptions struct {
+	transport Transport
+	trace     *DialTrace
+}
+
+// WithTransport makes Dial use established transport
+// instead of dialing TCP connection to Address and Port
+func WithTransport(t Transport) DialOption {
+	return func(options *dialOptions) {
+		options.transport = t
+	}
+}
+
+// timeoutError is returned, when read deadline of stream transport expires
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "telnet: read timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+type readResult struct {
+	data []byte
+	err  error
+}
+
+// streamTransport emulates read deadlines of a stream. Reading is done
+// by a background goroutine, so Read can return on deadline
+// while the stream is still blocked
+type streamTransport struct {
+	io.ReadWriteCloser
+	deadline time.Time
+	results  chan readResult
+	pending  []byte
+	err      error
+	done     chan struct{}
+	once     sync.Once
+}
+
+// StreamTransport adapts stream without read deadlines
+// (e.g. serial port) to Transport
+func StreamTransport(rwc io.ReadWriteCloser) Transport {
+	return &streamTransport{ReadWriteCloser: rwc, done: make(chan struct{})}
+}
+
+// Close closes the stream and stops background reading
+func (s *streamTransport) Close() error {
+	s.once.Do(func() { close(s.done) })
+	return s.ReadWriteCloser.Close()
+}
+
+func (s *streamTransport) SetReadDeadline(t time.Time) error {
+	s.deadline = t
+	return nil
+}
+
+func (s *streamTransport) Read(p []byte) (n int, err error) {
+	if len(s.pending) == 0 && s.err == nil {
+		err = s.wait()
+		if err != nil {
+			return
+		}
+	}
+
+	if len(s.pending) > 0 {
+		n = copy(p, s.pending)
+		s.pending = s.pending[n:]
+		return
+	}
+
+	return 0, s.err
+}
+
+// wait waits for the next result of background reading until deadline
+func (s *streamTransport) wait() error {
+	if s.results == nil {
+		s.results = make(chan readResult)
+		go s.readLoop()
+	}
+
+	var timeout <-chan time.Time
+	if !s.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(s.deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case result := <-s.results:
+		s.pending, s.err = result.data, result.err
+	case <-timeout:
+		return timeoutError{}
+	}
+
+	return nil
+}
+
+func (s *streamTransport) readLoop() {
+	for {
+		buf := make([]byte, 4096)
+		n, err := s.ReadWriteCloser.Read(buf)
+		select {
+		case s.results <- readResult{data: buf[:n], err: err}:
+		case <-s.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package telnet
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_WithTransport(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	// server
+	go func() {
+		server.Write([]byte("admin@R1:~$ "))
+
+		command := make([]byte, 64)
+		server.Read(command)
+		server.Write([]byte("up 3 days\r\nadmin@R1:~$ "))
+	}()
+
+	tc := &TelnetClient{ReadTimeout: time.Second}
+	err := tc.Dial(WithTransport(client))
+	if err != nil {
+		t.Fatalf("WithTransport: unexpected error of Dial: %v", err)
+	}
+	defer tc.Close()
+
+	stdout, err := tc.Execute("uptime")
+	if err != nil {
+		t.Fatalf("WithTransport: unexpected error of Execute: %v", err)
+	}
+	if want := []byte("up 3 days\r\n"); bytes.Compare(stdout, want) != 0 {
+		t.Errorf("WithTransport: wrong output: %q", stdout)
+	}
+}
+
+// pipeStream is a stream without read deadlines
+type pipeStream struct {
+	*io.PipeReader
+	io.Writer
+}
+
+func (p pipeStream) Close() error {
+	return p.PipeReader.Close()
+}
+
+func Test_StreamTransport(t *testing.T) {
+	r, w := io.Pipe()
+	transport := StreamTransport(pipeStream{PipeReader: r, Writer: ioutil.Discard})
+	defer transport.Close()
+
+	buf := make([]byte, 16)
+
+	transport.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err := transport.Read(buf)
+	if !isTimeout(err) {
+		t.Fatalf("StreamTransport: expected timeout, got %v", err)
+	}
+
+	go w.Write([]byte("Password:"))
+
+	transport.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := transport.Read(buf)
+	if err != nil || string(buf[:n]) != "Password:" {
+		t.Errorf("StreamTransport: unexpected result: %q, %v", buf[:n], err)
+	}
+
+	w.CloseWithError(io.ErrUnexpectedEOF)
+	_, err = transport.Read(buf)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("StreamTransport: expected stream error, got %v", err)
+	}
+}
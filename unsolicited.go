@@ -0,0 +1,40 @@
+package telnet
+
+import (
+	"bytes"
+	"time"
+)
+
+const defaultUnsolicitedWait = 10 * time.Millisecond
+
+// routeUnsolicited reads output received while no command was in flight
+// and passes it to OnUnsolicited line by line
+func (tc *TelnetClient) routeUnsolicited() (err error) {
+	wait := tc.UnsolicitedWait
+	if wait == 0 {
+		wait = defaultUnsolicitedWait
+	}
+
+	// Output of chatty device is routed until ReadTimeout,
+	// so command is sent anyway
+	var limit time.Time
+	if tc.ReadTimeout > 0 {
+		limit = time.Now().Add(tc.ReadTimeout)
+	}
+
+	output, err := tc.drain(wait, limit)
+	if err != nil {
+		return
+	}
+
+	// Whitespace-only lines are skipped, e.g. space after prompt,
+	// which is left unread by the previous command
+	for _, line := range bytes.Split(output, []byte{'\n'}) {
+		line = bytes.TrimRight(line, "\r")
+		if len(bytes.TrimSpace(line)) > 0 {
+			tc.OnUnsolicited(line)
+		}
+	}
+
+	return
+}
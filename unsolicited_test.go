@@ -0,0 +1,140 @@
+package telnet
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_TelnetClient_OnUnsolicited(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	var lines []string
+	tc := &TelnetClient{
+		ReadTimeout: time.Second,
+		Delimiter:   defaultDelimiter,
+		BannerRe:    defaultBannerRe,
+		OnUnsolicited: func(line []byte) {
+			lines = append(lines, string(line))
+		},
+		UnsolicitedWait: 20 * time.Millisecond,
+	}
+	tc.setConn(client)
+
+	// server
+	go func() {
+		server.Write([]byte("\r\nkernel: eth0: link down\r\nkernel: eth0: link up"))
+
+		command := make([]byte, 64)
+		server.Read(command)
+		server.Write([]byte("up 3 days\r\nadmin@RT-N14U:/tmp/home/root# "))
+	}()
+
+	stdout, err := tc.Execute("uptime")
+	if err != nil {
+		t.Fatalf("OnUnsolicited: unexpected error: %v", err)
+	}
+
+	if want := []byte("up 3 days\r\n"); bytes.Compare(stdout, want) != 0 {
+		t.Errorf("OnUnsolicited: wrong output: %q", stdout)
+	}
+	want := []string{"kernel: eth0: link down", "kernel: eth0: link up"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("OnUnsolicited: wrong lines:\n\t\tfact = %q\n\t\twant = %q", lines, want)
+	}
+}
+
+func Test_TelnetClient_OnUnsolicitedChatty(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tc := &TelnetClient{
+		ReadTimeout:     200 * time.Millisecond,
+		Delimiter:       defaultDelimiter,
+		BannerRe:        defaultBannerRe,
+		OnUnsolicited:   func(line []byte) {},
+		UnsolicitedWait: 20 * time.Millisecond,
+	}
+	tc.setConn(client)
+
+	// server prints debug messages faster than UnsolicitedWait,
+	// until command is received
+	received := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-received:
+				server.Write([]byte("up 3 days\r\nadmin@RT-N14U:/tmp/home/root# "))
+				return
+			default:
+			}
+			server.Write([]byte("debug: packet received\r\n"))
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	go func() {
+		command := make([]byte, 64)
+		server.Read(command)
+		close(received)
+	}()
+
+	start := time.Now()
+	stdout, err := tc.Execute("uptime")
+	if err != nil {
+		t.Fatalf("OnUnsolicited: unexpected error: %v", err)
+	}
+	if !bytes.HasSuffix(stdout, []byte("up 3 days\r\n")) {
+		t.Errorf("OnUnsolicited: wrong output: %q", stdout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("OnUnsolicited: command is delayed for %v", elapsed)
+	}
+}
+
+func Test_TelnetClient_OnUnsolicitedPrompt(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	var lines []string
+	tc := &TelnetClient{
+		ReadTimeout: time.Second,
+		Delimiter:   defaultDelimiter,
+		BannerRe:    defaultBannerRe,
+		OnUnsolicited: func(line []byte) {
+			lines = append(lines, string(line))
+		},
+		UnsolicitedWait: 20 * time.Millisecond,
+	}
+	tc.setConn(client)
+
+	commands := []string{"uptime", "uname", "whoami"}
+
+	// server
+	go func() {
+		for range commands {
+			command := make([]byte, 64)
+			server.Read(command)
+			server.Write([]byte("done\r\nadmin@RT-N14U:/tmp/home/root# "))
+		}
+	}()
+
+	for _, command := range commands {
+		stdout, err := tc.Execute(command)
+		if err != nil {
+			t.Fatalf("OnUnsolicited prompt: unexpected error of %s: %v", command, err)
+		}
+		if want := []byte("done\r\n"); bytes.Compare(stdout, want) != 0 {
+			t.Errorf("OnUnsolicited prompt: wrong output of %s: %q", command, stdout)
+		}
+	}
+
+	if len(lines) != 0 {
+		t.Errorf("OnUnsolicited prompt: rest of prompt is routed: %q", lines)
+	}
+}
@@ -0,0 +1,352 @@
+package telnet
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrWebSocketHandshake is returned, when server doesn't upgrade
+// connection to websocket
+var ErrWebSocketHandshake = errors.New("telnet: websocket handshake failed")
+
+// WebSocketOptions configures websocket transport
+type WebSocketOptions struct {
+	// Header is added to handshake request, e.g. Origin or Authorization
+	Header http.Header
+	// Subprotocols are offered in Sec-WebSocket-Protocol header
+	Subprotocols []string
+	// Timeout limits dialing and handshake
+	Timeout time.Duration
+	// PingInterval enables keepalive pings. Pings of server
+	// are always answered with pongs
+	PingInterval time.Duration
+	// TextFrames makes transport send text frames instead of binary,
+	// as some terminal frontends (e.g. xterm.js) expect
+	TextFrames bool
+	// TLSConfig is used for wss:// URLs
+	TLSConfig *tls.Config
+}
+
+// webSocket is a client websocket connection carrying telnet stream
+// in data frames. Frame boundaries aren't preserved
+type webSocket struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	options WebSocketOptions
+	// remaining is a number of unread payload bytes of current data frame
+	remaining uint64
+	mask      []byte
+	maskPos   int
+
+	wmu     sync.Mutex
+	done    chan struct{}
+	closing sync.Once
+}
+
+// DialWebSocket connects to ws:// or wss:// URL and returns
+// transport for Dial with WithTransport option
+func DialWebSocket(rawURL string, options WebSocketOptions) (Transport, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "wss" {
+			port = "443"
+		}
+	}
+	address := net.JoinHostPort(u.Hostname(), port)
+
+	dialer := &net.Dialer{Timeout: options.Timeout}
+	var conn net.Conn
+	switch u.Scheme {
+	case "ws":
+		conn, err = dialer.Dial("tcp", address)
+	case "wss":
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, options.TLSConfig)
+	default:
+		return nil, fmt.Errorf("telnet: unsupported websocket scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := newWebSocket(conn, u, options)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// newWebSocket performs handshake over established connection
+func newWebSocket(conn net.Conn, u *url.URL, options WebSocketOptions) (ws *webSocket, err error) {
+	nonce := make([]byte, 16)
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Host:       u.Host,
+	}
+	for name, values := range options.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(options.Subprotocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(options.Subprotocols, ", "))
+	}
+
+	if options.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(options.Timeout))
+	}
+	if err = req.Write(conn); err != nil {
+		return
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return nil, fmt.Errorf("%w: %s", ErrWebSocketHandshake, resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+
+	ws = &webSocket{
+		conn:    conn,
+		reader:  reader,
+		options: options,
+		done:    make(chan struct{}),
+	}
+	if options.PingInterval > 0 {
+		go ws.keepalive()
+	}
+
+	return
+}
+
+// acceptKey computes Sec-WebSocket-Accept value of handshake key
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func (ws *webSocket) SetReadDeadline(t time.Time) error {
+	return ws.conn.SetReadDeadline(t)
+}
+
+// Read returns payload of data frames. Control frames are handled
+// on the way: pings are answered with pongs, close frame ends the stream
+func (ws *webSocket) Read(p []byte) (n int, err error) {
+	for ws.remaining == 0 {
+		err = ws.nextFrame()
+		if err != nil {
+			return
+		}
+	}
+
+	if uint64(len(p)) > ws.remaining {
+		p = p[:ws.remaining]
+	}
+	n, err = ws.reader.Read(p)
+	ws.remaining -= uint64(n)
+
+	if ws.mask != nil {
+		for i := range p[:n] {
+			p[i] ^= ws.mask[ws.maskPos%4]
+			ws.maskPos++
+		}
+	}
+
+	return
+}
+
+// nextFrame reads header of the next frame. Header is peeked
+// before it is consumed, so reading can be resumed after timeout
+func (ws *webSocket) nextFrame() (err error) {
+	header, err := ws.reader.Peek(2)
+	if err != nil {
+		return
+	}
+
+	opcode := header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	size := 2
+	switch length {
+	case 126:
+		size += 2
+	case 127:
+		size += 8
+	}
+	if masked {
+		size += 4
+	}
+
+	header, err = ws.reader.Peek(size)
+	if err != nil {
+		return
+	}
+	switch length {
+	case 126:
+		length = uint64(binary.BigEndian.Uint16(header[2:]))
+	case 127:
+		length = binary.BigEndian.Uint64(header[2:])
+	}
+
+	var mask []byte
+	if masked {
+		mask = append([]byte{}, header[size-4:size]...)
+	}
+
+	switch opcode {
+	case wsContinuation, wsText, wsBinary:
+		ws.reader.Discard(size)
+		ws.remaining, ws.mask, ws.maskPos = length, mask, 0
+		return
+	}
+
+	// Control frames are short, so they are peeked completely
+	frame, err := ws.reader.Peek(size + int(length))
+	if err != nil {
+		return
+	}
+	payload := append([]byte{}, frame[size:]...)
+	ws.reader.Discard(len(frame))
+	if mask != nil {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	switch opcode {
+	case wsPing:
+		err = ws.writeFrame(wsPong, payload)
+	case wsClose:
+		ws.writeFrame(wsClose, payload)
+		err = io.EOF
+	}
+
+	return
+}
+
+func (ws *webSocket) Write(p []byte) (n int, err error) {
+	opcode := byte(wsBinary)
+	if ws.options.TextFrames {
+		opcode = wsText
+	}
+
+	err = ws.writeFrame(opcode, p)
+	if err != nil {
+		return
+	}
+
+	return len(p), nil
+}
+
+// writeFrame sends masked frame, as it is required from clients
+func (ws *webSocket) writeFrame(opcode byte, payload []byte) (err error) {
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|opcode)
+
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, 0x80|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+
+	mask := make([]byte, 4)
+	if _, err = io.ReadFull(rand.Reader, mask); err != nil {
+		return
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	ws.wmu.Lock()
+	defer ws.wmu.Unlock()
+
+	_, err = ws.conn.Write(frame)
+
+	return
+}
+
+// keepalive sends pings until connection is closed
+func (ws *webSocket) keepalive() {
+	ticker := time.NewTicker(ws.options.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if ws.writeFrame(wsPing, nil) != nil {
+				return
+			}
+		case <-ws.done:
+			return
+		}
+	}
+}
+
+// Close sends close frame and closes connection
+func (ws *webSocket) Close() (err error) {
+	ws.closing.Do(func() {
+		close(ws.done)
+		ws.writeFrame(wsClose, []byte{0x03, 0xe8})
+		err = ws.conn.Close()
+	})
+
+	return
+}
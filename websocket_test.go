@@ -0,0 +1,152 @@
+package telnet
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// readClientFrame reads masked frame sent by client
+func readClientFrame(r *bufio.Reader) (opcode byte, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	opcode = header[0] & 0x0f
+
+	// Frames sent in tests are short
+	mask := make([]byte, 4)
+	if _, err = io.ReadFull(r, mask); err != nil {
+		return
+	}
+	payload = make([]byte, header[1]&0x7f)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return
+}
+
+func serverFrame(fin bool, opcode byte, payload string) []byte {
+	if fin {
+		opcode |= 0x80
+	}
+	return append([]byte{opcode, byte(len(payload))}, payload...)
+}
+
+func Test_DialWebSocket(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("DialWebSocket: can't listen: %v", err)
+	}
+	defer ln.Close()
+
+	// server
+	type frame struct {
+		opcode  byte
+		payload string
+	}
+	received := make(chan []frame, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		req, err := http.ReadRequest(r)
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + acceptKey(req.Header.Get("Sec-WebSocket-Key")) +
+			"\r\n\r\n"))
+
+		conn.Write(serverFrame(true, wsPing, "hb"))
+		conn.Write(serverFrame(false, wsText, "R1 lo"))
+		conn.Write(serverFrame(true, wsContinuation, "gin:"))
+
+		var frames []frame
+		for len(frames) < 2 {
+			opcode, payload, err := readClientFrame(r)
+			if err != nil {
+				break
+			}
+			frames = append(frames, frame{opcode, string(payload)})
+		}
+		conn.Write(serverFrame(true, wsClose, "\x03\xe8"))
+		received <- frames
+	}()
+
+	ws, err := DialWebSocket("ws://"+ln.Addr().String()+"/console", WebSocketOptions{
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("DialWebSocket: unexpected error: %v", err)
+	}
+	defer ws.Close()
+
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	data := make([]byte, 0, 16)
+	buf := make([]byte, 16)
+	for len(data) < len("R1 login:") {
+		n, err := ws.Read(buf)
+		if err != nil {
+			t.Fatalf("DialWebSocket: unexpected error of Read: %v", err)
+		}
+		data = append(data, buf[:n]...)
+	}
+	if string(data) != "R1 login:" {
+		t.Errorf("DialWebSocket: wrong data: %q", data)
+	}
+
+	ws.Write([]byte("admin\r\n"))
+
+	if _, err = ws.Read(buf); err != io.EOF {
+		t.Errorf("DialWebSocket: expected EOF after close frame, got %v", err)
+	}
+
+	want := []frame{{wsPong, "hb"}, {wsBinary, "admin\r\n"}}
+	frames := <-received
+	if len(frames) != len(want) {
+		t.Fatalf("DialWebSocket: wrong frames: %v", frames)
+	}
+	for i := range want {
+		if frames[i] != want[i] {
+			t.Errorf("DialWebSocket: wrong frame %d: %v, want %v", i, frames[i], want[i])
+		}
+	}
+}
+
+func Test_DialWebSocket_handshakeFailed(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("DialWebSocket: can't listen: %v", err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		http.ReadRequest(bufio.NewReader(conn))
+		conn.Write([]byte("HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\n\r\n"))
+	}()
+
+	_, err = DialWebSocket("ws://"+ln.Addr().String(), WebSocketOptions{Timeout: time.Second})
+	if !errors.Is(err, ErrWebSocketHandshake) {
+		t.Errorf("DialWebSocket: unexpected error: %v", err)
+	}
+}